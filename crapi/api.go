@@ -2,44 +2,169 @@ package crapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
+// Caprover is a client for the API of a Caprover instance. It is safe for
+// concurrent use by multiple goroutines once created; Password must not be
+// modified after the first request, and a Caprover must not be copied after
+// first use. The address of the instance is read with Endpoint and changed
+// with SetEndpoint.
 type Caprover struct {
-	Endpoint string
 	Password string
-	Token    string
+
+	httpClient  *http.Client
+	logger      Logger
+	retryPolicy RetryPolicy
+	otpProvider OTPProvider
+	tokenCache  TokenCache
+
+	// mu guards endpoint, token and login.
+	mu       sync.Mutex
+	endpoint string
+	token    string
+	login    *loginCall
 }
 
-// NewCaproverInstance (endpoint string, password string) (Caprover, error): This
+// NewCaproverInstance (endpoint string, password string) (*Caprover, error): This
 // method is a constructor function that creates a new instance of the Caprover
 // struct. It takes an endpoint and password as parameters and initializes the
 // Caprover struct with the provided values. It also calls the Login method
 // internally to authenticate with the Caprover instance using the provided
-// credentials.
-func NewCaproverInstance(endpoint string, password string) (Caprover, error) {
-	cp := Caprover{
-		Endpoint: endpoint,
-		Password: password,
-		Token:    "",
+// credentials, unless a token for the endpoint is found in the cache set with
+// WithTokenCache. Options such as WithHTTPClient, WithTimeout and
+// WithTLSConfig control the http client used for every request.
+func NewCaproverInstance(endpoint string, password string, opts ...Option) (*Caprover, error) {
+	return NewCaproverInstanceContext(context.Background(), endpoint, password, opts...)
+}
+
+// NewCaproverInstanceContext is like NewCaproverInstance but performs the
+// initial login using the provided context.
+func NewCaproverInstanceContext(ctx context.Context, endpoint string, password string, opts ...Option) (*Caprover, error) {
+	cp := newCaprover(endpoint, password, opts)
+
+	if cp.tokenCache != nil {
+		token, err := cp.tokenCache.Load(endpoint)
+		if err != nil {
+			cp.log().Warn("unable to load cached caprover token", "error", err)
+		}
+		if token != "" {
+			cp.setToken(token)
+			return cp, nil
+		}
 	}
 
-	err := cp.Login()
+	err := cp.LoginContext(ctx)
 	if err != nil {
-		return Caprover{}, err
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// NewCaproverInstanceWithToken creates a new instance of the Caprover struct
+// authenticated with an existing token, e.g. one issued to a CI pipeline,
+// without knowing the dashboard password. No request is sent; an invalid token
+// is reported by the first call. As there is no password, an expired token
+// can't be renewed.
+func NewCaproverInstanceWithToken(endpoint string, token string, opts ...Option) (*Caprover, error) {
+	if token == "" {
+		return nil, errors.New("crapi: empty token")
 	}
 
+	cp := newCaprover(endpoint, "", opts)
+	cp.setToken(token)
+
 	return cp, nil
 }
 
+// newCaprover applies the options to a new, unauthenticated Caprover.
+func newCaprover(endpoint string, password string, opts []Option) *Caprover {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Caprover{
+		endpoint:    endpoint,
+		Password:    password,
+		httpClient:  o.buildHTTPClient(),
+		logger:      o.logger,
+		retryPolicy: o.retryPolicy,
+		otpProvider: o.otpProvider,
+		tokenCache:  o.tokenCache,
+	}
+}
+
+// Token returns the authentication token obtained by the last login.
+func (c *Caprover) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Caprover) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// SetEndpoint changes the address of the Caprover instance, e.g. once its
+// dashboard moved to a new root domain or to https. It is safe to call while
+// other requests are in flight, which keep the address they started with. The
+// current token stays valid and is cached under the new address.
+func (c *Caprover) SetEndpoint(endpoint string) {
+	c.mu.Lock()
+	c.endpoint = endpoint
+	token := c.token
+	c.mu.Unlock()
+
+	c.log().Info("changing caprover endpoint", "endpoint", endpoint)
+
+	if c.tokenCache != nil && token != "" {
+		if err := c.tokenCache.Store(endpoint, token); err != nil {
+			c.log().Warn("unable to cache caprover token", "error", err)
+		}
+	}
+}
+
+// Endpoint returns the current address of the Caprover instance.
+func (c *Caprover) Endpoint() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoint
+}
+
 func (c *Caprover) buildURL(path string) string {
-	return c.Endpoint + path
+	return c.Endpoint() + path
+}
+
+// log returns the logger configured for this instance, or one that discards
+// everything.
+func (c *Caprover) log() Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return nopLogger{}
+}
+
+// client returns the http client configured for this instance, falling back
+// to http.DefaultClient for a Caprover that was not built by
+// NewCaproverInstance.
+func (c *Caprover) client() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return http.DefaultClient
 }
 
 func (c *Caprover) addHeaders(req *http.Request) {
@@ -47,48 +172,199 @@ func (c *Caprover) addHeaders(req *http.Request) {
 	req.Header.Add("accept", "application/json, text/plain, */*")
 	req.Header.Add("x-namespace", "captain")
 
-	if c.Token != "" {
-		req.Header.Add("x-captain-auth", c.Token)
+	if token := c.Token(); token != "" {
+		req.Header.Add("x-captain-auth", token)
 	}
 }
 
-// Login () error: This method authenticates the client with the Caprover
-// instance. It sends a POST request to the Caprover login endpoint with the
-// provided password. If the login is successful, it retrieves and stores the
-// authentication token for subsequent requests.
-func (c *Caprover) Login() error {
-	fmt.Println("Attempting Login to Caprover Instance")
-
-	url := c.buildURL(URLLoginPath)
+// rawBody is a request payload sent as is, with its own content type, instead
+// of being JSON encoded. Its content is the concatenation of parts, which lets
+// a large payload be framed without copying it. It is kept in memory so that
+// the request can be replayed after a re-login or a retry.
+type rawBody struct {
+	contentType string
+	parts       [][]byte
+}
 
-	data := make(map[string]string)
-	data["password"] = c.Password
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
+// sendRequest builds a request for the given API path bound to ctx, JSON
+// encodes data as its body when it is not nil and returns the response status
+// code and headers along with the full response body. Cancelling ctx aborts
+// both the request and the body read.
+func (c *Caprover) sendRequest(ctx context.Context, method string, path string, data any) (int, http.Header, []byte, error) {
+	var payload io.Reader
+	contentType := ""
+	contentLength := int64(-1)
+	if raw, ok := data.(rawBody); ok {
+		readers := make([]io.Reader, len(raw.parts))
+		contentLength = 0
+		for i, part := range raw.parts {
+			readers[i] = bytes.NewReader(part)
+			contentLength += int64(len(part))
+		}
+		payload = io.MultiReader(readers...)
+		contentType = raw.contentType
+	} else if data != nil {
+		jsonEncode, err := json.Marshal(data)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		payload = bytes.NewBuffer(jsonEncode)
+	}
 
-	req, _ := http.NewRequest("POST", url, payload)
+	req, err := http.NewRequestWithContext(ctx, method, c.buildURL(path), payload)
+	if err != nil {
+		return 0, nil, nil, err
+	}
 
 	c.addHeaders(req)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+	}
+
+	res, err := c.client().Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	defer res.Body.Close()
 
-	res, err := http.DefaultClient.Do(req)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return res.StatusCode, res.Header, body, nil
+}
+
+// doRequest sends a request to the given API path and decodes the response
+// into rsp, which may be nil. It returns an *APIError when the response can't
+// be decoded or Caprover reports a failure. When Caprover rejects the token,
+// doRequest logs in again once and replays the request.
+func (c *Caprover) doRequest(ctx context.Context, method string, path string, data any, rsp any) error {
+	token := c.Token()
+
+	err := c.doRequestOnce(ctx, method, path, data, rsp)
+	if err == nil || path == URLLoginPath || c.Password == "" || !isAuthFailure(err) {
 		return err
 	}
 
-	if res.StatusCode != 200 {
-		return errors.New("login Error")
+	c.log().Warn("caprover token rejected, logging in again", "path", stripQuery(path))
+
+	if err := c.relogin(ctx, token); err != nil {
+		return err
 	}
 
-	defer res.Body.Close()
+	return c.doRequestOnce(ctx, method, path, data, rsp)
+}
+
+// doRequestOnce sends a request and decodes its response, retrying transient
+// failures according to the retry policy when the request may be retried.
+func (c *Caprover) doRequestOnce(ctx context.Context, method string, path string, data any, rsp any) error {
+	attempts := 1
+	if retryAllowed(ctx, method) {
+		attempts = c.retryPolicy.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		header, err := c.doAttempt(ctx, method, path, data, rsp)
+		if attempt >= attempts || !c.retryPolicy.shouldRetry(ctx, err) {
+			return err
+		}
+
+		delay := c.retryPolicy.backoff(attempt, header)
+		c.log().Warn("retrying caprover request",
+			"method", method,
+			"path", stripQuery(path),
+			"attempt", attempt,
+			"delay", delay,
+			"error", err,
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// doAttempt sends a single request and decodes its response. The response
+// headers are returned even on failure so that Retry-After can be honoured.
+func (c *Caprover) doAttempt(ctx context.Context, method string, path string, data any, rsp any) (http.Header, error) {
+	start := time.Now()
+
+	statusCode, header, body, err := c.sendRequest(ctx, method, path, data)
+	if err != nil {
+		c.log().Error("caprover request failed",
+			"method", method,
+			"path", stripQuery(path),
+			"latency", time.Since(start),
+			"error", err,
+		)
+		return nil, err
+	}
+
+	err = decodeResponse(path, statusCode, body, rsp)
+
+	args := []any{
+		"method", method,
+		"path", stripQuery(path),
+		"http_status", statusCode,
+		"latency", time.Since(start),
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		args = append(args, "status", apiErr.Status, "error", err)
+	}
+
+	c.log().Debug("caprover request", args...)
 
-	body, _ := io.ReadAll(res.Body)
+	return header, err
+}
+
+// Login () error: This method authenticates the client with the Caprover
+// instance. It sends a POST request to the Caprover login endpoint with the
+// provided password, and a one-time password when an OTP provider was
+// configured. If the login is successful, it retrieves and stores the
+// authentication token for subsequent requests.
+func (c *Caprover) Login() error {
+	return c.LoginContext(context.Background())
+}
+
+// LoginContext is like Login but binds the request to the provided context.
+func (c *Caprover) LoginContext(ctx context.Context) error {
+	c.log().Info("logging in to caprover", "endpoint", c.Endpoint())
+
+	data := make(map[string]string)
+	data["password"] = c.Password
+
+	if c.otpProvider != nil {
+		otpToken, err := c.otpProvider(ctx)
+		if err != nil {
+			return err
+		}
+		data["otpToken"] = otpToken
+	}
 
 	var rsp LoginResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
+	if err := c.doRequest(ctx, "POST", URLLoginPath, data, &rsp); err != nil {
+		return err
+	}
+
+	c.setToken(rsp.Data.Token)
+
+	if c.tokenCache != nil {
+		if err := c.tokenCache.Store(c.Endpoint(), rsp.Data.Token); err != nil {
+			c.log().Warn("unable to cache caprover token", "error", err)
+		}
 	}
 
-	c.Token = rsp.Data.Token
 	return nil
 }
 
@@ -97,29 +373,20 @@ func (c *Caprover) Login() error {
 // request to the Caprover app list endpoint and returns the list of applications
 // along with their details.
 func (c *Caprover) GetAppDetails() (ListAppResponse, error) {
-	fmt.Println("Getting App Details")
+	return c.GetAppDetailsContext(context.Background())
+}
 
-	url := c.buildURL(URLAppListPath)
+// GetAppDetailsContext is like GetAppDetails but binds the request to the
+// provided context.
+func (c *Caprover) GetAppDetailsContext(ctx context.Context) (ListAppResponse, error) {
+	c.log().Info("getting app details")
 
 	data := make(map[string]string)
 	data["password"] = c.Password
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
 
-	req, _ := http.NewRequest("GET", url, payload)
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return ListAppResponse{}, err
-	}
-
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
 	var rsp ListAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
+	if err := c.doRequest(ctx, "GET", URLAppListPath, data, &rsp); err != nil {
+		return ListAppResponse{}, err
 	}
 
 	return rsp, nil
@@ -129,15 +396,25 @@ func (c *Caprover) GetAppDetails() (ListAppResponse, error) {
 // a specific application by its name. It calls the GetAppDetails method
 // internally to get the list of all applications and then searches for the
 // application with the matching name. If found, it returns the application
-// details; otherwise, it returns an error.
+// details; otherwise, it returns an error wrapping ErrAppNotFound.
 func (c *Caprover) GetAppDetailFor(appName string) (AppDefinition, error) {
-	allDetails, _ := c.GetAppDetails()
+	return c.GetAppDetailForContext(context.Background(), appName)
+}
+
+// GetAppDetailForContext is like GetAppDetailFor but binds the request to the
+// provided context.
+func (c *Caprover) GetAppDetailForContext(ctx context.Context, appName string) (AppDefinition, error) {
+	allDetails, err := c.GetAppDetailsContext(ctx)
+	if err != nil {
+		return AppDefinition{}, err
+	}
+
 	for _, v := range allDetails.Data.AppDefinitions {
 		if strings.Compare(appName, v.AppName) == 0 {
 			return v, nil
 		}
 	}
-	return AppDefinition{}, errors.New("not found")
+	return AppDefinition{}, fmt.Errorf("%w: %s", ErrAppNotFound, appName)
 }
 
 // GetDefaultUpdateRequest (appName string) (UpdateAppRequest, error): This
@@ -145,25 +422,26 @@ func (c *Caprover) GetAppDetailFor(appName string) (AppDefinition, error) {
 // calls the GetAppDetails method internally to get the list of all applications
 // and then searches for the application with the matching name. If found, it
 // returns an UpdateAppRequest containing the default values for updating the
-// application; otherwise, it returns an error.
+// application; otherwise, it returns an error wrapping ErrAppNotFound.
 func (c *Caprover) GetDefaultUpdateRequest(appName string) (UpdateAppRequest, error) {
-	allDetails, _ := c.GetAppDetails()
+	return c.GetDefaultUpdateRequestContext(context.Background(), appName)
+}
 
-	var m AppDefinition
-	var found bool
-	for _, v := range allDetails.Data.AppDefinitions {
-		if strings.Compare(appName, v.AppName) == 0 {
-			m = v
-			found = true
-			break
-		}
+// GetDefaultUpdateRequestContext is like GetDefaultUpdateRequest but binds the
+// request to the provided context.
+func (c *Caprover) GetDefaultUpdateRequestContext(ctx context.Context, appName string) (UpdateAppRequest, error) {
+	m, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return UpdateAppRequest{}, err
 	}
 
-	if !found {
-		return UpdateAppRequest{}, errors.New("not found")
-	}
+	return newUpdateAppRequest(m), nil
+}
 
-	appRequest := UpdateAppRequest{
+// newUpdateAppRequest returns an UpdateAppRequest leaving the given app
+// definition unchanged.
+func newUpdateAppRequest(m AppDefinition) UpdateAppRequest {
+	return UpdateAppRequest{
 		AppName:                           m.AppName,
 		InstanceCount:                     m.InstanceCount,
 		CaptainDefinitionRelativeFilePath: m.CaptainDefinitionRelativeFilePath,
@@ -182,9 +460,9 @@ func (c *Caprover) GetDefaultUpdateRequest(appName string) (UpdateAppRequest, er
 		Description:           m.Description,
 		EnvVars:               m.EnvVars,
 		AppDeployTokenConfig:  m.AppDeployTokenConfig,
+		HTTPAuth:              m.HTTPAuth,
+		CustomNginxConfig:     m.CustomNginxConfig,
 	}
-
-	return appRequest, nil
 }
 
 // CreateApp (appName string, hasPersistentData bool) error: This method creates
@@ -193,40 +471,19 @@ func (c *Caprover) GetDefaultUpdateRequest(appName string) (UpdateAppRequest, er
 // parameters. If the creation is successful, it returns nil; otherwise, it
 // returns an error.
 func (c *Caprover) CreateApp(appName string, hasPersistentData bool) error {
-	fmt.Println("Attempting to create a new app")
+	return c.CreateAppContext(context.Background(), appName, hasPersistentData)
+}
 
-	url := c.buildURL(URLAppRegisterPath)
+// CreateAppContext is like CreateApp but binds the request to the provided
+// context.
+func (c *Caprover) CreateAppContext(ctx context.Context, appName string, hasPersistentData bool) error {
+	c.log().Info("creating app", "app", appName)
 
 	data := make(map[string]interface{})
 	data["appName"] = appName
 	data["hasPersistentData"] = hasPersistentData
 
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
-
-	req, _ := http.NewRequest("POST", url, payload)
-
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
-
-	if rsp.Status == 100 {
-		return nil
-	}
-
-	return errors.New(rsp.Description)
+	return c.doRequest(ctx, "POST", URLAppRegisterPath, data, nil)
 }
 
 // updateAppDetails (data UpdateAppRequest) error: This method updates the
@@ -234,37 +491,11 @@ func (c *Caprover) CreateApp(appName string, hasPersistentData bool) error {
 // the Caprover app update endpoint with the provided UpdateAppRequest payload.
 // If the update is successful, it returns nil; otherwise, it returns an error.
 // FOR INTERNAL USE ONLY
-func (c *Caprover) updateAppDetails(data UpdateAppRequest) error {
-	fmt.Println("Attempting to Update App Details")
+func (c *Caprover) updateAppDetails(ctx context.Context, data UpdateAppRequest) error {
+	c.log().Info("updating app details", "app", data.AppName)
 
-	url := c.buildURL(URLUpdateAppPath)
-
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
-
-	req, _ := http.NewRequest("POST", url, payload)
-
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
-
-	if rsp.Status == 100 {
-		return nil
-	}
-
-	return errors.New(rsp.Description)
+	// The update sends the whole app definition, so replaying it is safe.
+	return c.doRequest(ContextWithRetry(ctx), "POST", URLUpdateAppPath, data, nil)
 }
 
 // ForceBuild (token string) error: This method triggers a forced build for an
@@ -272,33 +503,17 @@ func (c *Caprover) updateAppDetails(data UpdateAppRequest) error {
 // app trigger build endpoint with the provided token parameter. If the build is
 // successful, it returns nil; otherwise, it returns an error.
 func (c *Caprover) ForceBuild(token string) error {
-	fmt.Println("Attempting to Force Build")
-
-	url := c.buildURL(URLAppTriggerBuild) + "?namespace=captain&token=" + token
-
-	req, _ := http.NewRequest("POST", url, nil)
-
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
+	return c.ForceBuildContext(context.Background(), token)
+}
 
-	if rsp.Status == 100 {
-		return nil
-	}
+// ForceBuildContext is like ForceBuild but binds the request to the provided
+// context.
+func (c *Caprover) ForceBuildContext(ctx context.Context, token string) error {
+	c.log().Info("forcing build")
 
-	return errors.New(rsp.Description)
+	path := URLAppTriggerBuild + "?namespace=captain&token=" + token
 
+	return c.doRequest(ctx, "POST", path, nil, nil)
 }
 
 // EnableBaseDomainSSL (appName string) error: This method enables SSL on the
@@ -306,37 +521,18 @@ func (c *Caprover) ForceBuild(token string) error {
 // base domain SSL endpoint with the provided appName parameter. If the SSL
 // enablement is successful, it returns nil; otherwise, it returns an error.
 func (c *Caprover) EnableBaseDomainSSL(appName string) error {
-	fmt.Println("Attempting to Enable SSL on Base Domain")
+	return c.EnableBaseDomainSSLContext(context.Background(), appName)
+}
 
-	url := c.buildURL(URLEnableBaseDomainSslPath)
+// EnableBaseDomainSSLContext is like EnableBaseDomainSSL but binds the request
+// to the provided context.
+func (c *Caprover) EnableBaseDomainSSLContext(ctx context.Context, appName string) error {
+	c.log().Info("enabling ssl on base domain", "app", appName)
 
 	data := make(map[string]string)
 	data["appName"] = appName
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
 
-	req, _ := http.NewRequest("POST", url, payload)
-
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
-
-	if rsp.Status == 100 {
-		return nil
-	}
-
-	return errors.New(rsp.Description)
+	return c.doRequest(ctx, "POST", URLEnableBaseDomainSslPath, data, nil)
 }
 
 // AddCustomDomain (appName string, domain string) error: This method adds a
@@ -344,39 +540,19 @@ func (c *Caprover) EnableBaseDomainSSL(appName string) error {
 // custom domain endpoint with the provided appName and domain parameters. If the
 // domain addition is successful, it returns nil; otherwise, it returns an error.
 func (c *Caprover) AddCustomDomain(appName string, domain string) error {
-	fmt.Println("Attempting to add a new domain")
+	return c.AddCustomDomainContext(context.Background(), appName, domain)
+}
 
-	url := c.buildURL(URLAddCustomDomainPath)
+// AddCustomDomainContext is like AddCustomDomain but binds the request to the
+// provided context.
+func (c *Caprover) AddCustomDomainContext(ctx context.Context, appName string, domain string) error {
+	c.log().Info("adding custom domain", "app", appName, "domain", domain)
 
 	data := make(map[string]string)
 	data["appName"] = appName
 	data["customDomain"] = domain
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
-
-	req, _ := http.NewRequest("POST", url, payload)
-
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
 
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
-
-	if rsp.Status == 100 {
-		return nil
-	}
-
-	return errors.New(rsp.Description)
+	return c.doRequest(ctx, "POST", URLAddCustomDomainPath, data, nil)
 }
 
 // EnableCustomDomainSSL (appName string, domain string) error: This method
@@ -385,44 +561,94 @@ func (c *Caprover) AddCustomDomain(appName string, domain string) error {
 // domain parameters. If the SSL enablement is successful, it returns nil;
 // otherwise, it returns an error.
 func (c *Caprover) EnableCustomDomainSSL(appName string, domain string) error {
-	fmt.Println("Attempting to Enable SSL on Custom Domain")
+	return c.EnableCustomDomainSSLContext(context.Background(), appName, domain)
+}
 
-	url := c.buildURL(URLEnableCustomDomainSslPath)
+// EnableCustomDomainSSLContext is like EnableCustomDomainSSL but binds the
+// request to the provided context.
+func (c *Caprover) EnableCustomDomainSSLContext(ctx context.Context, appName string, domain string) error {
+	c.log().Info("enabling ssl on custom domain", "app", appName, "domain", domain)
 
 	data := make(map[string]string)
 	data["appName"] = appName
 	data["customDomain"] = domain
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
 
-	req, _ := http.NewRequest("POST", url, payload)
+	return c.doRequest(ctx, "POST", URLEnableCustomDomainSslPath, data, nil)
+}
 
-	c.addHeaders(req)
+// RenameApp (oldName string, newName string) error: This method renames an
+// application, keeping its volumes, configuration and version history. The
+// new name is checked with ValidateAppName before the request is sent. It
+// sends a POST request to the Caprover app rename endpoint. If the rename is
+// successful, it returns nil; otherwise, it returns an error.
+func (c *Caprover) RenameApp(oldName string, newName string) error {
+	return c.RenameAppContext(context.Background(), oldName, newName)
+}
 
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
+// RenameAppContext is like RenameApp but binds the request to the provided
+// context.
+func (c *Caprover) RenameAppContext(ctx context.Context, oldName string, newName string) error {
+	if err := ValidateAppName(newName); err != nil {
 		return err
 	}
 
-	defer res.Body.Close()
+	c.log().Info("renaming app", "app", oldName, "new_name", newName)
 
-	body, _ := io.ReadAll(res.Body)
+	data := make(map[string]string)
+	data["oldAppName"] = oldName
+	data["newAppName"] = newName
 
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
+	return c.doRequest(ctx, "POST", URLAppRenamePath, data, nil)
+}
 
-	if rsp.Status == 100 {
-		return nil
+// RemoveCustomDomain (appName string, domain string) error: This method
+// removes a custom domain from an application. It sends a POST request to the
+// Caprover remove custom domain endpoint with the provided appName and domain
+// parameters. If the domain removal is successful, it returns nil; otherwise,
+// it returns an error.
+func (c *Caprover) RemoveCustomDomain(appName string, domain string) error {
+	return c.RemoveCustomDomainContext(context.Background(), appName, domain)
+}
+
+// RemoveCustomDomainContext is like RemoveCustomDomain but binds the request to
+// the provided context.
+func (c *Caprover) RemoveCustomDomainContext(ctx context.Context, appName string, domain string) error {
+	c.log().Info("removing custom domain", "app", appName, "domain", domain)
+
+	data := make(map[string]string)
+	data["appName"] = appName
+	data["customDomain"] = domain
+
+	return c.doRequest(ctx, "POST", URLRemoveCustomDomainPath, data, nil)
+}
+
+// ListCustomDomains (appName string) ([]CustomDomain, error): This method
+// retrieves the custom domains of an application and whether SSL is enabled
+// on each of them.
+func (c *Caprover) ListCustomDomains(appName string) ([]CustomDomain, error) {
+	return c.ListCustomDomainsContext(context.Background(), appName)
+}
+
+// ListCustomDomainsContext is like ListCustomDomains but binds the request to
+// the provided context.
+func (c *Caprover) ListCustomDomainsContext(ctx context.Context, appName string) ([]CustomDomain, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return nil, err
 	}
 
-	return errors.New(rsp.Description)
+	return app.CustomDomain, nil
 }
 
 // RestartApp restarts app with given appName
 func (c *Caprover) RestartApp(appName string) error {
-	err := c.updateAppDetails(UpdateAppRequest{
+	return c.RestartAppContext(context.Background(), appName)
+}
+
+// RestartAppContext is like RestartApp but binds the request to the provided
+// context.
+func (c *Caprover) RestartAppContext(ctx context.Context, appName string) error {
+	err := c.updateAppDetails(ctx, UpdateAppRequest{
 		AppName: appName,
 	})
 
@@ -430,7 +656,11 @@ func (c *Caprover) RestartApp(appName string) error {
 }
 
 func (c *Caprover) UpdateContainerHTTPPort(appName string, newPort int) error {
-	err := c.updateAppDetails(UpdateAppRequest{
+	return c.UpdateContainerHTTPPortContext(context.Background(), appName, newPort)
+}
+
+func (c *Caprover) UpdateContainerHTTPPortContext(ctx context.Context, appName string, newPort int) error {
+	err := c.updateAppDetails(ctx, UpdateAppRequest{
 		AppName:           appName,
 		ContainerHTTPPort: newPort,
 	})
@@ -439,7 +669,11 @@ func (c *Caprover) UpdateContainerHTTPPort(appName string, newPort int) error {
 }
 
 func (c *Caprover) EnableWebsocketSupport(appName string) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.EnableWebsocketSupportContext(context.Background(), appName)
+}
+
+func (c *Caprover) EnableWebsocketSupportContext(ctx context.Context, appName string) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.WebsocketSupport = true
 
@@ -447,13 +681,17 @@ func (c *Caprover) EnableWebsocketSupport(appName string) error {
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) EnableForceHTTPS(appName string) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.EnableForceHTTPSContext(context.Background(), appName)
+}
+
+func (c *Caprover) EnableForceHTTPSContext(ctx context.Context, appName string) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.ForceSsl = true
 
@@ -461,13 +699,17 @@ func (c *Caprover) EnableForceHTTPS(appName string) error {
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) DisableWebsocketSupport(appName string) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.DisableWebsocketSupportContext(context.Background(), appName)
+}
+
+func (c *Caprover) DisableWebsocketSupportContext(ctx context.Context, appName string) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.WebsocketSupport = false
 
@@ -475,13 +717,17 @@ func (c *Caprover) DisableWebsocketSupport(appName string) error {
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) DisableForceHTTPS(appName string) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.DisableForceHTTPSContext(context.Background(), appName)
+}
+
+func (c *Caprover) DisableForceHTTPSContext(ctx context.Context, appName string) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.ForceSsl = false
 
@@ -489,13 +735,17 @@ func (c *Caprover) DisableForceHTTPS(appName string) error {
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) TurnInstanceCountZero(appName string) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.TurnInstanceCountZeroContext(context.Background(), appName)
+}
+
+func (c *Caprover) TurnInstanceCountZeroContext(ctx context.Context, appName string) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.InstanceCount = 0
 
@@ -503,13 +753,17 @@ func (c *Caprover) TurnInstanceCountZero(appName string) error {
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) TurnInstanceCountOne(appName string) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.TurnInstanceCountOneContext(context.Background(), appName)
+}
+
+func (c *Caprover) TurnInstanceCountOneContext(ctx context.Context, appName string) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.InstanceCount = 1
 
@@ -517,13 +771,17 @@ func (c *Caprover) TurnInstanceCountOne(appName string) error {
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) UpdateGitRepoInfo(appName string, repoInfo AppRepoInfo) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.UpdateGitRepoInfoContext(context.Background(), appName, repoInfo)
+}
+
+func (c *Caprover) UpdateGitRepoInfoContext(ctx context.Context, appName string, repoInfo AppRepoInfo) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
 
 	currentConfig.AppPushWebhook.RepoInfo = repoInfo
 
@@ -531,13 +789,20 @@ func (c *Caprover) UpdateGitRepoInfo(appName string, repoInfo AppRepoInfo) error
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) UpdateResourceConstraint(appName string, memoryInMB int64, cpuInUnits float64) error {
-	currentConfig, err := c.GetDefaultUpdateRequest(appName)
+	return c.UpdateResourceConstraintContext(context.Background(), appName, memoryInMB, cpuInUnits)
+}
+
+func (c *Caprover) UpdateResourceConstraintContext(ctx context.Context, appName string, memoryInMB int64, cpuInUnits float64) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
 
 	suo, err := json.Marshal(ServiceUpdateOverride{
 		TaskTemplate: SUOTaskTemplate{
@@ -556,30 +821,23 @@ func (c *Caprover) UpdateResourceConstraint(appName string, memoryInMB int64, cp
 		return err
 	}
 
-	err = c.updateAppDetails(currentConfig)
+	err = c.updateAppDetails(ctx, currentConfig)
 
 	return err
 }
 
 func (c *Caprover) GetBuildLogs(appName string) (string, error) {
-	fmt.Println("Getting Build Logs")
+	return c.GetBuildLogsContext(context.Background(), appName)
+}
 
-	url := c.buildURL(URLAppBuildLog) + "/" + appName + "/"
+func (c *Caprover) GetBuildLogsContext(ctx context.Context, appName string) (string, error) {
+	c.log().Info("getting build logs", "app", appName)
 
-	req, _ := http.NewRequest("GET", url, nil)
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-
-	defer res.Body.Close()
+	path := URLAppBuildLog + "/" + url.PathEscape(appName) + "/"
 
-	body, _ := io.ReadAll(res.Body)
 	var rsp AppBuildLogResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
+	if err := c.doRequest(ctx, "GET", path, nil, &rsp); err != nil {
+		return "", err
 	}
 
 	logLines := strings.Join(rsp.Data.Logs.Lines, "\n")
@@ -588,24 +846,17 @@ func (c *Caprover) GetBuildLogs(appName string) (string, error) {
 }
 
 func (c *Caprover) GetAppLogs(appName string) (string, error) {
-	fmt.Println("Getting App Logs")
-
-	url := c.buildURL(URLAppBuildLog) + "/" + appName + "/logs"
-
-	req, _ := http.NewRequest("GET", url, nil)
-	c.addHeaders(req)
+	return c.GetAppLogsContext(context.Background(), appName)
+}
 
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", err
-	}
+func (c *Caprover) GetAppLogsContext(ctx context.Context, appName string) (string, error) {
+	c.log().Info("getting app logs", "app", appName)
 
-	defer res.Body.Close()
+	path := URLAppBuildLog + "/" + url.PathEscape(appName) + "/logs"
 
-	body, _ := io.ReadAll(res.Body)
 	var rsp AppLogResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
+	if err := c.doRequest(ctx, "GET", path, nil, &rsp); err != nil {
+		return "", err
 	}
 
 	logLines := rsp.Data.Logs
@@ -618,36 +869,16 @@ func (c *Caprover) GetAppLogs(appName string) (string, error) {
 // `appName` parameter. If the deletion is successful, it returns nil; otherwise,
 // it returns an error.
 func (c *Caprover) RemoveApp(appName string) error {
-	fmt.Println("Attempting to Remove an APP")
+	return c.RemoveAppContext(context.Background(), appName)
+}
 
-	url := c.buildURL(URLAppDeletePath)
+// RemoveAppContext is like RemoveApp but binds the request to the provided
+// context.
+func (c *Caprover) RemoveAppContext(ctx context.Context, appName string) error {
+	c.log().Info("removing app", "app", appName)
 
 	data := make(map[string]string)
 	data["appName"] = appName
-	jsonEncode, _ := json.Marshal(data)
-	payload := bytes.NewBuffer(jsonEncode)
-
-	req, _ := http.NewRequest("POST", url, payload)
-
-	c.addHeaders(req)
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-
-	defer res.Body.Close()
-
-	body, _ := io.ReadAll(res.Body)
-
-	var rsp GenericAppResponse
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		log.Fatal(err)
-	}
-
-	if rsp.Status == 100 {
-		return nil
-	}
 
-	return errors.New(rsp.Description)
+	return c.doRequest(ctx, "POST", URLAppDeletePath, data, nil)
 }
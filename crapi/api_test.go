@@ -0,0 +1,353 @@
+package crapi_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+// newClient starts a fake Caprover server, closed when the test ends, and
+// returns it along with a client logged in to it.
+func newClient(t *testing.T, opts ...crapi.Option) (*crapitest.Server, *crapi.Caprover) {
+	t.Helper()
+
+	srv := crapitest.NewServer()
+	t.Cleanup(srv.Close)
+
+	caprover, err := srv.Client(opts...)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return srv, caprover
+}
+
+// countRequests returns how many requests with the given method and path the
+// server received.
+func countRequests(srv *crapitest.Server, request string) int {
+	n := 0
+	for _, v := range srv.Requests() {
+		if v == request {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLogin(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if caprover.Token() == "" {
+		t.Fatal("no token after login")
+	}
+	if n := countRequests(srv, "POST "+crapi.URLLoginPath); n != 1 {
+		t.Errorf("got %d login requests, want 1", n)
+	}
+}
+
+func TestReloginAfterTokenExpiry(t *testing.T) {
+	srv, caprover := newClient(t)
+	token := caprover.Token()
+
+	srv.ExpireTokens()
+
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if caprover.Token() == token {
+		t.Error("token wasn't renewed")
+	}
+	if n := countRequests(srv, "POST "+crapi.URLLoginPath); n != 2 {
+		t.Errorf("got %d login requests, want 2", n)
+	}
+}
+
+func TestReloginOnlyOnce(t *testing.T) {
+	// Every token is rejected, even a fresh one.
+	srv := loginServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status":%d,"description":"Auth token corrupted","data":{}}`, crapi.StatusAuthTokenInvalid)
+	})
+
+	transport := &countingTransport{}
+	caprover, err := crapi.NewCaproverInstance(srv.URL, "password",
+		crapi.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := caprover.GetAppDetails(); !errors.Is(err, crapi.ErrUnauthorized) {
+		t.Errorf("got %v, want ErrUnauthorized", err)
+	}
+	// The login, the request, the re-login and the replayed request.
+	if n := transport.requests.Load(); n != 4 {
+		t.Errorf("got %d requests, want 4", n)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	caprover, err := crapi.NewCaproverInstanceWithToken(srv.URL, srv.IssueToken())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(srv, "POST "+crapi.URLLoginPath); n != 0 {
+		t.Errorf("got %d login requests, want 0", n)
+	}
+
+	// Without a password an expired token can't be renewed.
+	srv.ExpireTokens()
+	if _, err := caprover.GetAppDetails(); !errors.Is(err, crapi.ErrUnauthorized) {
+		t.Errorf("got %v, want ErrUnauthorized", err)
+	}
+
+	if _, err := crapi.NewCaproverInstanceWithToken(srv.URL, ""); err == nil {
+		t.Error("empty token accepted")
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{
+			name: "app already exists",
+			call: func() error { return caprover.CreateApp("app", false) },
+			want: crapi.ErrAppAlreadyExists,
+		},
+		{
+			name: "invalid app name",
+			call: func() error { return caprover.CreateApp("Not_Valid", false) },
+			want: crapi.ErrInvalidAppName,
+		},
+		{
+			name: "app not found from caprover",
+			call: func() error { return caprover.EnableBaseDomainSSL("missing") },
+			want: crapi.ErrAppNotFound,
+		},
+		{
+			name: "app not found from lookup",
+			call: func() error { _, err := caprover.GetAppDetailFor("missing"); return err },
+			want: crapi.ErrAppNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameApp(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("old", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.RenameApp("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := srv.App("old"); ok {
+		t.Error("old app still exists")
+	}
+	if _, ok := srv.App("new"); !ok {
+		t.Error("new app doesn't exist")
+	}
+
+	// Invalid names are rejected before any request is sent.
+	before := len(srv.Requests())
+	if err := caprover.RenameApp("new", "captain"); !errors.Is(err, crapi.ErrInvalidAppName) {
+		t.Errorf("got %v, want ErrInvalidAppName", err)
+	}
+	if len(srv.Requests()) != before {
+		t.Error("request sent for an invalid name")
+	}
+}
+
+func TestValidateAppName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"app", true},
+		{"my-app-2", true},
+		{"a", true},
+		{"", false},
+		{"App", false},
+		{"2app", false},
+		{"app-", false},
+		{"my--app", false},
+		{"my_app", false},
+		{"captain", false},
+		{"registry", false},
+		{"a234567890123456789012345678901234567890123456789", true},
+		{"a2345678901234567890123456789012345678901234567890", false},
+	}
+
+	for _, tt := range tests {
+		err := crapi.ValidateAppName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateAppName(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, crapi.ErrInvalidAppName) {
+			t.Errorf("ValidateAppName(%q) = %v, want ErrInvalidAppName", tt.name, err)
+		}
+	}
+}
+
+func TestCustomDomains(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.AddCustomDomain("app", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.EnableCustomDomainSSL("app", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	domains, err := caprover.ListCustomDomains("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].PublicDomain != "example.com" || !domains[0].HasSsl {
+		t.Fatalf("got %+v", domains)
+	}
+
+	if err := caprover.RemoveCustomDomain("app", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if domains, _ := caprover.ListCustomDomains("app"); len(domains) != 0 {
+		t.Errorf("got %+v after removal", domains)
+	}
+}
+
+func TestForceBuild(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	app, _ := srv.App("app")
+
+	if err := caprover.ForceBuild(app.AppPushWebhook.PushWebhookToken); err != nil {
+		t.Fatal(err)
+	}
+	if app, _ := srv.App("app"); len(app.Versions) != 1 {
+		t.Errorf("got versions %+v", app.Versions)
+	}
+
+	logs, err := caprover.GetBuildLogs("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs, "Build has finished successfully") {
+		t.Errorf("got build logs %q", logs)
+	}
+
+	if err := caprover.ForceBuild("wrong"); err == nil {
+		t.Error("wrong webhook token accepted")
+	}
+}
+
+func TestSetEndpoint(t *testing.T) {
+	_, caprover := newClient(t)
+
+	other := crapitest.NewServer()
+	defer other.Close()
+
+	caprover.SetEndpoint(other.URL)
+	if got := caprover.Endpoint(); got != other.URL {
+		t.Errorf("got endpoint %s, want %s", got, other.URL)
+	}
+	if caprover.Token() == "" {
+		t.Fatal("token lost")
+	}
+
+	// The other server doesn't know the token, so the client logs in again on
+	// the new endpoint.
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(other, "POST "+crapi.URLLoginPath); n != 1 {
+		t.Errorf("got %d login requests on the new endpoint, want 1", n)
+	}
+}
+
+func TestAppLogs(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetAppLogs("app", "hello\nworld")
+
+	logs, err := caprover.GetAppLogs("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs != "hello\nworld" {
+		t.Errorf("got %q", logs)
+	}
+}
+
+func TestAppSettings(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	srv.AddApp(crapi.AppDefinition{
+		AppName:       "app",
+		InstanceCount: 1,
+		EnvVars:       []crapi.EnvVarInformation{{Key: "KEEP", Value: "me"}},
+	})
+
+	for _, update := range []func(string) error{
+		caprover.EnableWebsocketSupport,
+		caprover.EnableForceHTTPS,
+		caprover.TurnInstanceCountZero,
+	} {
+		if err := update("app"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app, _ := srv.App("app")
+	if !app.WebsocketSupport || !app.ForceSsl || app.InstanceCount != 0 {
+		t.Errorf("got %+v", app)
+	}
+	// Every update sends the whole definition, which must keep the rest.
+	if len(app.EnvVars) != 1 || app.EnvVars[0].Key != "KEEP" {
+		t.Errorf("got env vars %+v", app.EnvVars)
+	}
+
+	if err := caprover.RemoveApp("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.App("app"); ok {
+		t.Error("app still exists")
+	}
+	if err := caprover.RemoveApp("app"); !errors.Is(err, crapi.ErrAppNotFound) {
+		t.Errorf("got %v, want ErrAppNotFound", err)
+	}
+}
@@ -0,0 +1,30 @@
+package crapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// appNamePattern matches the characters and boundaries Caprover allows in app
+// names: lowercase letters, digits and hyphens, starting with a letter and
+// ending with a letter or digit.
+var appNamePattern = regexp.MustCompile(`^[a-z]([a-z0-9-]*[a-z0-9])?$`)
+
+// reservedAppNames are used by Caprover for its own services.
+var reservedAppNames = map[string]bool{
+	"captain":  true,
+	"registry": true,
+}
+
+// ValidateAppName checks a name against the rules Caprover enforces for app
+// names: fewer than 50 characters, only lowercase letters, digits and single
+// hyphens, starting with a letter and ending with a letter or digit, and not
+// one of the names Caprover reserves, captain and registry. It returns an
+// error wrapping ErrInvalidAppName otherwise.
+func ValidateAppName(name string) error {
+	if len(name) >= 50 || !appNamePattern.MatchString(name) || strings.Contains(name, "--") || reservedAppNames[name] {
+		return fmt.Errorf("%w: %q", ErrInvalidAppName, name)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+)
+
+// loginCall is a login in flight. Goroutines that need a fresh token while it
+// runs wait for done instead of sending their own login request.
+type loginCall struct {
+	done chan struct{}
+	err  error
+}
+
+// isAuthFailure reports whether err means Caprover rejected the token sent
+// with the request.
+func isAuthFailure(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Status == StatusAuthTokenInvalid || apiErr.Status == StatusErrorNotAuthorized
+}
+
+// isContextError reports whether err comes from a cancelled context or an
+// expired deadline.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// relogin obtains a new token after a request sent with staleToken was
+// rejected. Concurrent callers share a single login request, and nothing is
+// sent when the token has already been replaced since staleToken was used.
+func (c *Caprover) relogin(ctx context.Context, staleToken string) error {
+	c.mu.Lock()
+	if c.token != staleToken {
+		c.mu.Unlock()
+		return nil
+	}
+
+	if call := c.login; call != nil {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		// The shared login runs on the context of the caller that started
+		// it. When that context ended, try again on this one instead of
+		// failing a caller whose own context is still live.
+		if isContextError(call.err) && ctx.Err() == nil {
+			return c.relogin(ctx, staleToken)
+		}
+		return call.err
+	}
+
+	call := &loginCall{done: make(chan struct{})}
+	c.login = call
+	c.mu.Unlock()
+
+	call.err = c.LoginContext(ctx)
+
+	c.mu.Lock()
+	c.login = nil
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.err
+}
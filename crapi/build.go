@@ -0,0 +1,305 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// BuildStatus is the outcome of a build waited for with WaitForBuild.
+type BuildStatus int
+
+const (
+	// BuildSucceeded means the build finished and the new version is deployed.
+	BuildSucceeded BuildStatus = iota
+	// BuildFailed means Caprover reported the build as failed.
+	BuildFailed
+	// BuildTimedOut means the build was still running when the wait timed out.
+	BuildTimedOut
+)
+
+func (s BuildStatus) String() string {
+	switch s {
+	case BuildSucceeded:
+		return "succeeded"
+	case BuildFailed:
+		return "failed"
+	case BuildTimedOut:
+		return "timed out"
+	}
+	return "unknown"
+}
+
+// BuildResult is returned by WaitForBuild and DeployAndWait.
+type BuildResult struct {
+	Status BuildStatus
+	// DeployedVersion is the version of the app deployed once the wait ended.
+	DeployedVersion int
+	// LastLogLines holds the last build log lines, up to WaitOptions.TailLines.
+	LastLogLines []string
+}
+
+// WaitOptions controls WaitForBuild and DeployAndWait. The zero value is ready
+// to use.
+type WaitOptions struct {
+	// PollInterval is the delay between two build status requests. Defaults
+	// to 2 seconds.
+	PollInterval time.Duration
+	// Timeout bounds the wait, after which BuildTimedOut is returned. Zero
+	// means the wait is only bounded by the context.
+	Timeout time.Duration
+	// TailLines is the number of log lines kept in BuildResult.LastLogLines.
+	// Defaults to 50.
+	TailLines int
+	// OnLogLine, if set, is called with every new build log line, in order.
+	OnLogLine func(line string)
+}
+
+func (o WaitOptions) pollInterval() time.Duration {
+	if o.PollInterval > 0 {
+		return o.PollInterval
+	}
+	return 2 * time.Second
+}
+
+func (o WaitOptions) tailLines() int {
+	if o.TailLines > 0 {
+		return o.TailLines
+	}
+	return 50
+}
+
+// GetBuildStatus (appName string) (AppBuildLogData, error): This method
+// retrieves whether an application is being built, whether its last build
+// failed and the most recent build log lines.
+func (c *Caprover) GetBuildStatus(appName string) (AppBuildLogData, error) {
+	return c.GetBuildStatusContext(context.Background(), appName)
+}
+
+// GetBuildStatusContext is like GetBuildStatus but binds the request to the
+// provided context.
+func (c *Caprover) GetBuildStatusContext(ctx context.Context, appName string) (AppBuildLogData, error) {
+	path := URLAppBuildLog + "/" + url.PathEscape(appName) + "/"
+
+	var rsp AppBuildLogResponse
+	if err := c.doRequest(ctx, "GET", path, nil, &rsp); err != nil {
+		return AppBuildLogData{}, err
+	}
+
+	return rsp.Data, nil
+}
+
+// WaitForBuild polls the build status of an application until its next build
+// finishes, streaming the build log lines to opts.OnLogLine as they appear. A
+// build that doesn't finish within opts.Timeout or before the context deadline
+// is reported as BuildTimedOut; other context errors and failed requests are
+// returned as errors.
+//
+// The deployed version and build log position are recorded on entry, and
+// success is only reported once the build was seen running, wrote new log
+// lines or deployed a new version. Call it right after starting the build,
+// e.g. after ForceBuild, or use DeployAndWait, which records them before
+// starting the deployment.
+func (c *Caprover) WaitForBuild(ctx context.Context, appName string, opts WaitOptions) (BuildResult, error) {
+	watch, status, err := c.watchBuild(ctx, appName)
+	if err != nil {
+		return BuildResult{}, err
+	}
+	if status.IsAppBuilding {
+		// Caprover clears the build logs when a build starts, so the lines
+		// already there belong to the running build.
+		watch.started = true
+		watch.nextLine = status.Logs.FirstLineNumber
+		watch.lastLine = ""
+	}
+
+	return c.waitForBuild(ctx, appName, opts, watch)
+}
+
+// DeployAndWait starts a deployment of an application with deploy, for
+// instance a call to DeployImageContext, and waits for the resulting build
+// like WaitForBuild.
+func (c *Caprover) DeployAndWait(ctx context.Context, appName string, deploy func(ctx context.Context) error, opts WaitOptions) (BuildResult, error) {
+	watch, _, err := c.watchBuild(ctx, appName)
+	if err != nil {
+		return BuildResult{}, err
+	}
+
+	if err := deploy(ctx); err != nil {
+		return BuildResult{}, err
+	}
+
+	return c.waitForBuild(ctx, appName, opts, watch)
+}
+
+// watchBuild records the deployed version and build log position of an
+// application, so that the previous build isn't mistaken for the next one.
+func (c *Caprover) watchBuild(ctx context.Context, appName string) (buildWatch, AppBuildLogData, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return buildWatch{}, AppBuildLogData{}, err
+	}
+
+	status, err := c.GetBuildStatusContext(ctx, appName)
+	if err != nil {
+		return buildWatch{}, AppBuildLogData{}, err
+	}
+
+	return buildWatch{
+		previousVersion: app.DeployedVersion,
+		nextLine:        status.Logs.FirstLineNumber + len(status.Logs.Lines),
+		lastLine:        lastLine(status.Logs.Lines),
+		building:        status.IsAppBuilding,
+		failed:          status.IsBuildFailed,
+	}, status, nil
+}
+
+// lastLine returns the last of the given log lines, if any.
+func lastLine(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[len(lines)-1]
+}
+
+// buildWatch tracks the progress of a build between two polls.
+type buildWatch struct {
+	// previousVersion is the version deployed before the build was started.
+	previousVersion int
+	// nextLine is the number of the first log line not seen yet.
+	nextLine int
+	// lastLine is the log line numbered nextLine-1.
+	lastLine string
+	// started is set once there is evidence that the build has started.
+	started bool
+	// building and failed are the build state seen on the last poll.
+	building bool
+	failed   bool
+	tail     []string
+}
+
+// observe records a poll of the build status and the log lines that weren't
+// seen yet.
+func (w *buildWatch) observe(status AppBuildLogData, opts WaitOptions) {
+	logs := status.Logs
+
+	// Caprover clears the build log when a build starts and numbers its lines
+	// from zero again, so the position recorded so far belongs to a previous
+	// build once a build was seen starting or failing, the numbering went
+	// backwards or the last line seen was replaced.
+	if w.restarted(status) {
+		w.nextLine = logs.FirstLineNumber
+		w.started = true
+	}
+	w.building = status.IsAppBuilding
+	w.failed = status.IsBuildFailed
+	if status.IsAppBuilding {
+		w.started = true
+	}
+
+	for i, line := range logs.Lines {
+		if logs.FirstLineNumber+i < w.nextLine {
+			continue
+		}
+
+		w.started = true
+		w.nextLine = logs.FirstLineNumber + i + 1
+		w.lastLine = line
+		w.tail = append(w.tail, line)
+		if len(w.tail) > opts.tailLines() {
+			w.tail = w.tail[len(w.tail)-opts.tailLines():]
+		}
+		if opts.OnLogLine != nil {
+			opts.OnLogLine(line)
+		}
+	}
+}
+
+// restarted reports whether the build log of a poll was cleared by a build
+// started since the previous poll.
+func (w *buildWatch) restarted(status AppBuildLogData) bool {
+	if (status.IsAppBuilding && !w.building) || (status.IsBuildFailed && !w.failed) {
+		return true
+	}
+
+	logs := status.Logs
+	if logs.FirstLineNumber+len(logs.Lines) < w.nextLine {
+		return true
+	}
+	last := w.nextLine - 1 - logs.FirstLineNumber
+	return last >= 0 && last < len(logs.Lines) && logs.Lines[last] != w.lastLine
+}
+
+func (c *Caprover) waitForBuild(ctx context.Context, appName string, opts WaitOptions, watch buildWatch) (BuildResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	for {
+		status, err := c.GetBuildStatusContext(ctx, appName)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return BuildResult{Status: BuildTimedOut, LastLogLines: watch.tail}, nil
+		}
+		if err != nil {
+			return BuildResult{}, err
+		}
+
+		watch.observe(status, opts)
+
+		if !status.IsAppBuilding && watch.started {
+			return c.buildResult(ctx, appName, status, watch)
+		}
+
+		if !status.IsAppBuilding && !watch.started {
+			// The build may have started and finished between two polls
+			// without leaving new log lines, in which case the deployed
+			// version moved on and the whole log belongs to that build.
+			app, err := c.GetAppDetailForContext(ctx, appName)
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return BuildResult{}, err
+			}
+			if err == nil && app.DeployedVersion != watch.previousVersion {
+				watch.nextLine = status.Logs.FirstLineNumber
+				watch.observe(status, opts)
+				return c.buildResult(ctx, appName, status, watch)
+			}
+		}
+
+		c.log().Debug("waiting for build", "app", appName, "building", status.IsAppBuilding)
+
+		timer := time.NewTimer(opts.pollInterval())
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return BuildResult{Status: BuildTimedOut, LastLogLines: watch.tail}, nil
+			}
+			return BuildResult{}, ctx.Err()
+		}
+	}
+}
+
+// buildResult builds the result of a finished build.
+func (c *Caprover) buildResult(ctx context.Context, appName string, status AppBuildLogData, watch buildWatch) (BuildResult, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return BuildResult{}, err
+	}
+
+	result := BuildResult{
+		Status:          BuildSucceeded,
+		DeployedVersion: app.DeployedVersion,
+		LastLogLines:    watch.tail,
+	}
+	if status.IsBuildFailed {
+		result.Status = BuildFailed
+	}
+
+	c.log().Info("build finished", "app", appName, "status", result.Status.String(), "version", result.DeployedVersion)
+
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package crapi_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// fastWait polls the fake server without delay.
+var fastWait = crapi.WaitOptions{PollInterval: time.Millisecond, Timeout: 5 * time.Second}
+
+func TestDeployAndWait(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetBuildPolls(2)
+
+	var lines []string
+	opts := fastWait
+	opts.OnLogLine = func(line string) { lines = append(lines, line) }
+
+	result, err := caprover.DeployAndWait(context.Background(), "app", func(ctx context.Context) error {
+		return caprover.DeployImageContext(ctx, "app", "nginx:1.25")
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Status != crapi.BuildSucceeded || result.DeployedVersion != 0 {
+		t.Errorf("got %+v", result)
+	}
+	if len(lines) != 3 || !strings.Contains(lines[2], "nginx:1.25") {
+		t.Errorf("got log lines %q", lines)
+	}
+	if len(result.LastLogLines) != 3 {
+		t.Errorf("got last log lines %q", result.LastLogLines)
+	}
+}
+
+func TestDeployAndWaitConsecutiveBuilds(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every build numbers its log lines from zero, so the lines of the second
+	// build have the numbers of lines already seen in the first one.
+	for version, image := range []string{"nginx:1.24", "nginx:1.25"} {
+		srv.SetBuildPolls(2)
+
+		var lines []string
+		opts := fastWait
+		opts.OnLogLine = func(line string) { lines = append(lines, line) }
+
+		result, err := caprover.DeployAndWait(context.Background(), "app", func(ctx context.Context) error {
+			return caprover.DeployImageContext(ctx, "app", image)
+		}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Status != crapi.BuildSucceeded || result.DeployedVersion != version {
+			t.Errorf("%s: got %+v", image, result)
+		}
+		if len(lines) != 3 || !strings.Contains(lines[2], image) {
+			t.Errorf("%s: got log lines %q", image, lines)
+		}
+	}
+}
+
+func TestDeployAndWaitFailedBuild(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.DeployImage("app", "nginx:1.24"); err != nil {
+		t.Fatal(err)
+	}
+	srv.FailNextBuild()
+
+	result, err := caprover.DeployAndWait(context.Background(), "app", func(ctx context.Context) error {
+		return caprover.DeployImageContext(ctx, "app", "nginx:1.25")
+	}, fastWait)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Status != crapi.BuildFailed || result.DeployedVersion != 0 {
+		t.Errorf("got %+v", result)
+	}
+}
+
+func TestWaitForBuildTimesOut(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetBuildPolls(1000)
+	if err := caprover.DeployImage("app", "nginx:1.25"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := caprover.WaitForBuild(context.Background(), "app", crapi.WaitOptions{
+		PollInterval: time.Millisecond,
+		Timeout:      50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != crapi.BuildTimedOut {
+		t.Errorf("got %v, want %v", result.Status, crapi.BuildTimedOut)
+	}
+
+	// An app that was never built has nothing to wait for.
+	if err := caprover.CreateApp("other", false); err != nil {
+		t.Fatal(err)
+	}
+	result, err = caprover.WaitForBuild(context.Background(), "other", crapi.WaitOptions{
+		PollInterval: time.Millisecond,
+		Timeout:      50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != crapi.BuildTimedOut {
+		t.Errorf("never built: got %v, want %v", result.Status, crapi.BuildTimedOut)
+	}
+}
+
+func TestWaitForRunningBuild(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.DeployImage("app", "nginx:1.24"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.SetBuildPolls(3)
+	if err := caprover.DeployImage("app", "nginx:1.25"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the lines of the running build are reported, even though they
+	// were written before the wait started.
+	var lines []string
+	opts := fastWait
+	opts.OnLogLine = func(line string) { lines = append(lines, line) }
+
+	result, err := caprover.WaitForBuild(context.Background(), "app", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Status != crapi.BuildSucceeded || result.DeployedVersion != 1 {
+		t.Errorf("got %+v", result)
+	}
+	if len(lines) != 3 || strings.Contains(strings.Join(lines, "\n"), "nginx:1.24") {
+		t.Errorf("got log lines %q", lines)
+	}
+}
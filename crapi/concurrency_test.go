@@ -0,0 +1,129 @@
+package crapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+func TestConcurrentCallsAndRelogin(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	caprover, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 200)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := caprover.GetAppDetailFor("app"); err != nil {
+					errs <- err
+				}
+				if _, err := caprover.GetAppDetails(); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	// A single expiry while calls are in flight: every call must recover with
+	// the token of a shared re-login.
+	time.Sleep(time.Millisecond)
+	srv.ExpireTokens()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := countRequests(srv, "POST "+crapi.URLLoginPath); n > 2 {
+		t.Errorf("got %d login requests, want at most 2", n)
+	}
+}
+
+// loginGate is a RoundTripper holding the first login request it sees until
+// the context of that request ends.
+type loginGate struct {
+	mu      sync.Mutex
+	held    bool
+	started chan struct{}
+}
+
+func (g *loginGate) RoundTrip(req *http.Request) (*http.Response, error) {
+	g.mu.Lock()
+	hold := req.URL.Path == crapi.URLLoginPath && !g.held && g.started != nil
+	if hold {
+		g.held = true
+	}
+	g.mu.Unlock()
+
+	if hold {
+		close(g.started)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (g *loginGate) arm() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.started = make(chan struct{})
+	return g.started
+}
+
+func TestReloginSurvivesCancelledLeader(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	gate := &loginGate{}
+	caprover, err := srv.Client(crapi.WithHTTPClient(&http.Client{Transport: gate}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := gate.arm()
+	srv.ExpireTokens()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := caprover.GetAppDetailsContext(ctx)
+		leaderErr <- err
+	}()
+	<-started
+
+	waiterErr := make(chan error, 1)
+	go func() {
+		_, err := caprover.GetAppDetailsContext(context.Background())
+		waiterErr <- err
+	}()
+
+	// Give the waiter time to join the login held by the gate.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader: got %v, want context.Canceled", err)
+	}
+	if err := <-waiterErr; err != nil {
+		t.Errorf("waiter: %v", err)
+	}
+}
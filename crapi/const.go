@@ -12,6 +12,45 @@ const (
 	URLEnableBaseDomainSslPath   = "/api/v2/user/apps/appDefinitions/enablebasedomainssl"
 	URLAddCustomDomainPath       = "/api/v2/user/apps/appDefinitions/customdomain"
 	URLEnableCustomDomainSslPath = "/api/v2/user/apps/appDefinitions/enablecustomdomainssl"
+	URLRemoveCustomDomainPath    = "/api/v2/user/apps/appDefinitions/removecustomdomain"
 	URLAppBuildLog               = "/api/v2/user/apps/appData"
+	URLAppDataPath               = "/api/v2/user/apps/appData"
 	URLAppDeletePath             = "/api/v2/user/apps/appDefinitions/delete"
+	URLAppRenamePath             = "/api/v2/user/apps/appDefinitions/rename"
+	URLRegistriesPath            = "/api/v2/user/registries"
+	URLRegistriesInsertPath      = "/api/v2/user/registries/insert"
+	URLRegistriesUpdatePath      = "/api/v2/user/registries/update"
+	URLRegistriesDeletePath      = "/api/v2/user/registries/delete"
+	URLRegistriesSetPushPath     = "/api/v2/user/registries/setpush"
+	URLSystemNodesPath           = "/api/v2/user/system/nodes"
+	URLSystemInfoPath            = "/api/v2/user/system/info"
+	URLChangeRootDomainPath      = "/api/v2/user/system/changerootdomain"
+	URLEnableRootSslPath         = "/api/v2/user/system/enablessl"
+	URLForceSslPath              = "/api/v2/user/system/forcessl"
+	URLOneClickAppListPath       = "/api/v2/user/oneclickapps/template/list"
+	URLOneClickAppTemplatePath   = "/api/v2/user/oneclickapps/template/app"
+)
+
+// Status codes reported by Caprover in the status field of every response.
+const (
+	StatusOK              = 100
+	StatusOKDeployStarted = 101
+	StatusOKPartially     = 102
+
+	StatusErrorGeneric               = 1000
+	StatusErrorCaptainNotInitialized = 1001
+	StatusErrorUserNotInitialized    = 1101
+	StatusErrorNotAuthorized         = 1102
+	StatusErrorAlreadyExist          = 1103
+	StatusErrorBadName               = 1104
+	StatusWrongPassword              = 1105
+	StatusAuthTokenInvalid           = 1106
+	StatusVerificationFailed         = 1107
+	StatusIllegalOperation           = 1108
+	StatusBuildError                 = 1109
+	StatusIllegalParameter           = 1110
+	StatusErrorNotFound              = 1111
+	StatusErrorAuthenticationFailed  = 1112
+	StatusPasswordBackOff            = 1113
+	StatusErrorOtpRequired           = 1114
 )
@@ -0,0 +1,91 @@
+package crapi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// loginServer starts a server which accepts any login and hands every other
+// request to handler, closed when the test ends. The request body is read
+// first so that the server notices when the client goes away.
+func loginServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == crapi.URLLoginPath {
+			io.WriteString(w, `{"status":100,"description":"","data":{"token":"token"}}`)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestContextCancelsRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		hang http.HandlerFunc
+	}{
+		{
+			name: "no response",
+			hang: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+		},
+		{
+			name: "partial body",
+			hang: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, `{"status":100,"data":`)
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := loginServer(t, tt.hang)
+
+			caprover, err := crapi.NewCaproverInstance(srv.URL, "password")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err = caprover.GetAppDetailsContext(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got %v, want context.DeadlineExceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("returned after %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestContextCancelsLogin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err := crapi.NewCaproverInstanceContext(ctx, srv.URL, "password"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
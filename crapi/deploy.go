@@ -0,0 +1,127 @@
+package crapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/url"
+)
+
+// DeployTarball (appName string, tar io.Reader) error: This method deploys an
+// application from a source tarball, the way the Caprover CLI does. The tar,
+// optionally gzipped, must contain a captain-definition file at its root. It
+// is uploaded to the Caprover app data endpoint and the build runs in the
+// background once the upload is accepted.
+func (c *Caprover) DeployTarball(appName string, tar io.Reader) error {
+	return c.DeployTarballContext(context.Background(), appName, tar)
+}
+
+// DeployTarballContext is like DeployTarball but binds the request to the
+// provided context.
+func (c *Caprover) DeployTarballContext(ctx context.Context, appName string, tar io.Reader) error {
+	// The tarball is read into memory so that the upload can be replayed after
+	// a re-login.
+	tarball, err := io.ReadAll(tar)
+	if err != nil {
+		return err
+	}
+
+	return c.deployTarball(ctx, appName, tarball)
+}
+
+// deployTarball uploads the tarball as the sourceFile of a multipart form.
+// Only the form framing around it is allocated, the tarball isn't copied.
+func (c *Caprover) deployTarball(ctx context.Context, appName string, tarball []byte) error {
+	c.log().Info("deploying tarball", "app", appName, "size", len(tarball))
+
+	var framing bytes.Buffer
+	form := multipart.NewWriter(&framing)
+
+	if _, err := form.CreateFormFile("sourceFile", "captain-source.tar.gz"); err != nil {
+		return err
+	}
+	headerLen := framing.Len()
+	if err := form.Close(); err != nil {
+		return err
+	}
+	header, footer := framing.Bytes()[:headerLen], framing.Bytes()[headerLen:]
+
+	path := URLAppDataPath + "/" + url.PathEscape(appName) + "?detached=1"
+
+	return c.doRequest(ctx, "POST", path, rawBody{
+		contentType: form.FormDataContentType(),
+		parts:       [][]byte{header, tarball, footer},
+	}, nil)
+}
+
+// DeployDirectory (appName string, dir string) error: This method packs the
+// source directory with PackSourceDirectory and deploys it with DeployTarball.
+func (c *Caprover) DeployDirectory(appName string, dir string) error {
+	return c.DeployDirectoryContext(context.Background(), appName, dir)
+}
+
+// DeployDirectoryContext is like DeployDirectory but binds the request to the
+// provided context.
+func (c *Caprover) DeployDirectoryContext(ctx context.Context, appName string, dir string) error {
+	var tarball bytes.Buffer
+	if err := PackSourceDirectory(dir, &tarball); err != nil {
+		return err
+	}
+
+	return c.deployTarball(ctx, appName, tarball.Bytes())
+}
+
+// DeployImage (appName string, imageName string) error: This method deploys a
+// prebuilt Docker image, e.g. registry.example.com/api:1.4.2, to an
+// application. It is a shortcut for DeployCaptainDefinition with only the
+// image name set.
+func (c *Caprover) DeployImage(appName string, imageName string) error {
+	return c.DeployImageContext(context.Background(), appName, imageName)
+}
+
+// DeployImageContext is like DeployImage but binds the request to the provided
+// context.
+func (c *Caprover) DeployImageContext(ctx context.Context, appName string, imageName string) error {
+	return c.DeployCaptainDefinitionContext(ctx, appName, CaptainDefinition{
+		SchemaVersion: 2,
+		ImageName:     imageName,
+	})
+}
+
+// DeployCaptainDefinition (appName string, def CaptainDefinition) error: This
+// method deploys an application from an inline captain-definition. It sends a
+// POST request to the Caprover app data endpoint and the build runs in the
+// background once the definition is accepted. A zero SchemaVersion defaults
+// to 2.
+func (c *Caprover) DeployCaptainDefinition(appName string, def CaptainDefinition) error {
+	return c.DeployCaptainDefinitionContext(context.Background(), appName, def)
+}
+
+// DeployCaptainDefinitionContext is like DeployCaptainDefinition but binds the
+// request to the provided context.
+func (c *Caprover) DeployCaptainDefinitionContext(ctx context.Context, appName string, def CaptainDefinition) error {
+	c.log().Info("deploying captain definition", "app", appName, "image", def.ImageName)
+
+	if def.ImageName == "" && len(def.DockerfileLines) == 0 && def.TemplateID == "" {
+		return errors.New("crapi: captain definition needs an image name, dockerfile lines or a template id")
+	}
+	if def.SchemaVersion == 0 {
+		def.SchemaVersion = 2
+	}
+
+	content, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]string)
+	data["captainDefinitionContent"] = string(content)
+	data["gitHash"] = ""
+
+	path := URLAppDataPath + "/" + url.PathEscape(appName) + "?detached=1"
+
+	return c.doRequest(ctx, "POST", path, data, nil)
+}
@@ -0,0 +1,108 @@
+package crapi_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+func TestDeployDirectory(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"captain-definition": `{"schemaVersion":2,"dockerfileLines":["FROM nginx"]}`,
+		"index.html":         "hello",
+	})
+	if err := caprover.DeployDirectory("app", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := strings.Join(srv.BuildLogs("app"), "\n")
+	if !strings.Contains(logs, "uploaded tarball") {
+		t.Errorf("got build logs %q", logs)
+	}
+
+	// A tarball without a captain-definition is rejected by Caprover.
+	if err := caprover.DeployTarball("app", bytes.NewReader(nil)); err == nil {
+		t.Error("empty tarball accepted")
+	}
+}
+
+func TestDeployDirectoryReplayedAfterRelogin(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"captain-definition": `{"schemaVersion":2,"dockerfileLines":["FROM nginx"]}`,
+	})
+
+	// The first upload is rejected with the expired token, then sent again in
+	// full after the re-login.
+	srv.ExpireTokens()
+	if err := caprover.DeployDirectory("app", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "POST " + crapi.URLAppDataPath + "/app"
+	if n := countRequests(srv, path); n != 2 {
+		t.Errorf("got %d uploads, want 2", n)
+	}
+	if app, _ := srv.App("app"); len(app.Versions) != 1 {
+		t.Errorf("got versions %+v", app.Versions)
+	}
+}
+
+func TestDeployImage(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.DeployImage("app", "nginx:1.25"); err != nil {
+		t.Fatal(err)
+	}
+
+	app, _ := srv.App("app")
+	if len(app.Versions) != 1 || app.Versions[0].DeployedImageName != "nginx:1.25" {
+		t.Errorf("got versions %+v", app.Versions)
+	}
+}
+
+func TestDeployCaptainDefinition(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	err := caprover.DeployCaptainDefinition("app", crapi.CaptainDefinition{
+		DockerfileLines: []string{"FROM nginx", "COPY . /usr/share/nginx/html"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := strings.Join(srv.BuildLogs("app"), "\n")
+	if !strings.Contains(logs, "dockerfile lines") {
+		t.Errorf("got build logs %q", logs)
+	}
+
+	// Nothing to build is rejected before any request is sent.
+	before := len(srv.Requests())
+	if err := caprover.DeployCaptainDefinition("app", crapi.CaptainDefinition{}); err == nil {
+		t.Error("empty captain definition accepted")
+	}
+	if len(srv.Requests()) != before {
+		t.Error("request sent for an empty captain definition")
+	}
+}
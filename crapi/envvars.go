@@ -0,0 +1,144 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// GetEnvVars (appName string) ([]EnvVarInformation, error): This method
+// retrieves the environment variables of an application in the order they are
+// defined.
+func (c *Caprover) GetEnvVars(appName string) ([]EnvVarInformation, error) {
+	return c.GetEnvVarsContext(context.Background(), appName)
+}
+
+// GetEnvVarsContext is like GetEnvVars but binds the request to the provided
+// context.
+func (c *Caprover) GetEnvVarsContext(ctx context.Context, appName string) ([]EnvVarInformation, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.EnvVars, nil
+}
+
+// SetEnvVars (appName string, vars map[string]string) error: This method adds
+// or updates environment variables of an application in a single update
+// request. Existing variables keep their position and value unless listed in
+// vars; new ones are appended in key order.
+func (c *Caprover) SetEnvVars(appName string, vars map[string]string) error {
+	return c.SetEnvVarsContext(context.Background(), appName, vars)
+}
+
+// SetEnvVarsContext is like SetEnvVars but binds the request to the provided
+// context.
+func (c *Caprover) SetEnvVarsContext(ctx context.Context, appName string, vars map[string]string) error {
+	if _, ok := vars[""]; ok {
+		return errors.New("crapi: environment variable key can't be empty")
+	}
+
+	return c.updateEnvVars(ctx, appName, func(current []EnvVarInformation) []EnvVarInformation {
+		merged := make([]EnvVarInformation, 0, len(current)+len(vars))
+		seen := make(map[string]bool, len(vars))
+		for _, v := range current {
+			if value, ok := vars[v.Key]; ok {
+				v.Value = value
+				seen[v.Key] = true
+			}
+			merged = append(merged, v)
+		}
+
+		keys := make([]string, 0, len(vars))
+		for key := range vars {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			merged = append(merged, EnvVarInformation{Key: key, Value: vars[key]})
+		}
+		return merged
+	})
+}
+
+// UnsetEnvVars (appName string, keys ...string) error: This method removes
+// environment variables from an application in a single update request. Keys
+// that aren't defined are ignored.
+func (c *Caprover) UnsetEnvVars(appName string, keys ...string) error {
+	return c.UnsetEnvVarsContext(context.Background(), appName, keys...)
+}
+
+// UnsetEnvVarsContext is like UnsetEnvVars but binds the request to the
+// provided context.
+func (c *Caprover) UnsetEnvVarsContext(ctx context.Context, appName string, keys ...string) error {
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		remove[key] = true
+	}
+
+	return c.updateEnvVars(ctx, appName, func(current []EnvVarInformation) []EnvVarInformation {
+		kept := make([]EnvVarInformation, 0, len(current))
+		for _, v := range current {
+			if !remove[v.Key] {
+				kept = append(kept, v)
+			}
+		}
+		return kept
+	})
+}
+
+// ReplaceEnvVars (appName string, vars []EnvVarInformation) error: This method
+// replaces all the environment variables of an application, in the given
+// order, in a single update request.
+func (c *Caprover) ReplaceEnvVars(appName string, vars []EnvVarInformation) error {
+	return c.ReplaceEnvVarsContext(context.Background(), appName, vars)
+}
+
+// ReplaceEnvVarsContext is like ReplaceEnvVars but binds the request to the
+// provided context.
+func (c *Caprover) ReplaceEnvVarsContext(ctx context.Context, appName string, vars []EnvVarInformation) error {
+	if err := validateEnvVars(vars); err != nil {
+		return err
+	}
+
+	return c.updateEnvVars(ctx, appName, func([]EnvVarInformation) []EnvVarInformation {
+		return append([]EnvVarInformation{}, vars...)
+	})
+}
+
+// updateEnvVars replaces the environment variables of an application with the
+// result of change applied to the current ones.
+func (c *Caprover) updateEnvVars(ctx context.Context, appName string, change func([]EnvVarInformation) []EnvVarInformation) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	envVars := change(currentConfig.EnvVars)
+	if err := validateEnvVars(envVars); err != nil {
+		return err
+	}
+	currentConfig.EnvVars = envVars
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// validateEnvVars rejects empty and duplicate keys.
+func validateEnvVars(vars []EnvVarInformation) error {
+	seen := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		if v.Key == "" {
+			return errors.New("crapi: environment variable key can't be empty")
+		}
+		if seen[v.Key] {
+			return fmt.Errorf("%w: %s", ErrDuplicateEnvVar, v.Key)
+		}
+		seen[v.Key] = true
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package crapi_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+func TestEnvVars(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name   string
+		change func() error
+		want   []crapi.EnvVarInformation
+	}{
+		{
+			name:   "set appends in key order",
+			change: func() error { return caprover.SetEnvVars("app", map[string]string{"B": "2", "A": "1"}) },
+			want:   []crapi.EnvVarInformation{{Key: "A", Value: "1"}, {Key: "B", Value: "2"}},
+		},
+		{
+			name:   "set keeps the position of existing keys",
+			change: func() error { return caprover.SetEnvVars("app", map[string]string{"C": "3", "A": "one"}) },
+			want:   []crapi.EnvVarInformation{{Key: "A", Value: "one"}, {Key: "B", Value: "2"}, {Key: "C", Value: "3"}},
+		},
+		{
+			name:   "unset ignores unknown keys",
+			change: func() error { return caprover.UnsetEnvVars("app", "B", "MISSING") },
+			want:   []crapi.EnvVarInformation{{Key: "A", Value: "one"}, {Key: "C", Value: "3"}},
+		},
+		{
+			name: "replace keeps the given order",
+			change: func() error {
+				return caprover.ReplaceEnvVars("app", []crapi.EnvVarInformation{{Key: "Z", Value: "26"}, {Key: "Y", Value: "25"}})
+			},
+			want: []crapi.EnvVarInformation{{Key: "Z", Value: "26"}, {Key: "Y", Value: "25"}},
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		got, err := caprover.GetEnvVars("app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: got %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestEnvVarsInvalid(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	err := caprover.ReplaceEnvVars("app", []crapi.EnvVarInformation{{Key: "A"}, {Key: "A"}})
+	if !errors.Is(err, crapi.ErrDuplicateEnvVar) {
+		t.Errorf("got %v, want ErrDuplicateEnvVar", err)
+	}
+
+	if err := caprover.SetEnvVars("app", map[string]string{"": "value"}); err == nil {
+		t.Error("empty key accepted")
+	}
+
+	if err := caprover.SetEnvVars("missing", map[string]string{"A": "1"}); !errors.Is(err, crapi.ErrAppNotFound) {
+		t.Errorf("got %v, want ErrAppNotFound", err)
+	}
+}
@@ -0,0 +1,177 @@
+package crapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrAppNotFound is returned when the requested app does not exist on the
+	// Caprover instance.
+	ErrAppNotFound = errors.New("crapi: app not found")
+
+	// ErrUnauthorized is returned when Caprover rejects the password or the
+	// authentication token.
+	ErrUnauthorized = errors.New("crapi: unauthorized")
+
+	// ErrAppAlreadyExists is returned when creating an app whose name is
+	// already taken.
+	ErrAppAlreadyExists = errors.New("crapi: app already exists")
+
+	// ErrVersionNotFound is returned when rolling back to a version of an app
+	// that doesn't exist or has no image.
+	ErrVersionNotFound = errors.New("crapi: version not found")
+
+	// ErrDuplicateEnvVar is returned when environment variables would end up
+	// with the same key defined twice.
+	ErrDuplicateEnvVar = errors.New("crapi: duplicate environment variable")
+
+	// ErrNoPersistentData is returned when managing the volumes of an app that
+	// was created without persistent data.
+	ErrNoPersistentData = errors.New("crapi: app has no persistent data")
+
+	// ErrVolumeNotFound is returned when removing a volume that isn't mounted.
+	ErrVolumeNotFound = errors.New("crapi: volume not found")
+
+	// ErrPortInUse is returned when publishing a host port that is already
+	// published by an app.
+	ErrPortInUse = errors.New("crapi: port already in use")
+
+	// ErrOTPRequired is returned when logging in to a Caprover instance with
+	// two-factor authentication enabled without a one-time password.
+	ErrOTPRequired = errors.New("crapi: one-time password required")
+
+	// ErrNodeNotFound is returned when the cluster has no node with the given
+	// id.
+	ErrNodeNotFound = errors.New("crapi: node not found")
+
+	// ErrInvalidAppName is returned when an app name doesn't follow the rules
+	// of Caprover, see ValidateAppName.
+	ErrInvalidAppName = errors.New("crapi: invalid app name")
+
+	// ErrDecode is returned when a response body is not the JSON Caprover
+	// normally replies with, e.g. an HTML error page from the nginx front.
+	ErrDecode = errors.New("crapi: unable to decode response")
+)
+
+// APIError describes a failed call to the Caprover API. It is returned both
+// when Caprover answers with a non-success status and when its response can't
+// be decoded. Use errors.Is with the sentinel errors of this package to test
+// for common failures and errors.As to inspect the details.
+type APIError struct {
+	// HTTPStatus is the status code of the http response.
+	HTTPStatus int
+	// Status is the status code reported by Caprover in the response body,
+	// e.g. StatusErrorGeneric. It is zero when the body couldn't be decoded.
+	Status int
+	// Description is the human readable message reported by Caprover.
+	Description string
+	// Endpoint is the API path that was called, without its query string.
+	Endpoint string
+	// Body is the raw response body.
+	Body []byte
+	// Err is the underlying error, if any. It wraps ErrDecode when the body
+	// couldn't be decoded.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v (endpoint %s, http status %d)", e.Err, e.Endpoint, e.HTTPStatus)
+	}
+	return fmt.Sprintf("crapi: %s (status %d, endpoint %s, http status %d)", e.Description, e.Status, e.Endpoint, e.HTTPStatus)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches one of the sentinel errors of this
+// package based on the Caprover and http status codes.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		switch e.Status {
+		case StatusErrorNotAuthorized, StatusWrongPassword, StatusAuthTokenInvalid, StatusErrorAuthenticationFailed:
+			return true
+		}
+		return e.HTTPStatus == 401 || e.HTTPStatus == 403
+	case ErrOTPRequired:
+		return e.Status == StatusErrorOtpRequired
+	case ErrAppAlreadyExists:
+		return e.Status == StatusErrorAlreadyExist
+	case ErrInvalidAppName:
+		return e.Status == StatusErrorBadName
+	case ErrAppNotFound:
+		// Caprover reports unknown apps with the generic error status, so the
+		// description has to be looked at as well.
+		if e.Status == StatusErrorNotFound {
+			return true
+		}
+		description := strings.ToLower(e.Description)
+		return e.Status == StatusErrorGeneric &&
+			(strings.Contains(description, "not found") || strings.Contains(description, "could not be found"))
+	}
+	return false
+}
+
+// stripQuery returns path without its query string, which may carry secrets
+// such as the webhook token of ForceBuild.
+func stripQuery(path string) string {
+	endpoint, _, _ := strings.Cut(path, "?")
+	return endpoint
+}
+
+// isSuccessStatus reports whether the Caprover status code denotes success.
+func isSuccessStatus(status int) bool {
+	return status == StatusOK || status == StatusOKDeployStarted || status == StatusOKPartially
+}
+
+// decodeResponse checks the http and Caprover status of a response and
+// unmarshals its body into rsp, which may be nil when the caller doesn't need
+// the data.
+func decodeResponse(path string, httpStatus int, body []byte, rsp any) error {
+	endpoint := stripQuery(path)
+
+	var base struct {
+		Status      int    `json:"status"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(body, &base); err != nil {
+		return &APIError{
+			HTTPStatus: httpStatus,
+			Endpoint:   endpoint,
+			Body:       body,
+			Err:        fmt.Errorf("%w: %w", ErrDecode, err),
+		}
+	}
+
+	if httpStatus < 200 || httpStatus > 299 || !isSuccessStatus(base.Status) {
+		return &APIError{
+			HTTPStatus:  httpStatus,
+			Status:      base.Status,
+			Description: base.Description,
+			Endpoint:    endpoint,
+			Body:        body,
+		}
+	}
+
+	if rsp == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, rsp); err != nil {
+		return &APIError{
+			HTTPStatus:  httpStatus,
+			Status:      base.Status,
+			Description: base.Description,
+			Endpoint:    endpoint,
+			Body:        body,
+			Err:         fmt.Errorf("%w: %w", ErrDecode, err),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package crapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *crapi.APIError
+		want error
+	}{
+		{"wrong password", &crapi.APIError{HTTPStatus: 200, Status: crapi.StatusWrongPassword}, crapi.ErrUnauthorized},
+		{"invalid token", &crapi.APIError{HTTPStatus: 200, Status: crapi.StatusAuthTokenInvalid}, crapi.ErrUnauthorized},
+		{"http 401", &crapi.APIError{HTTPStatus: 401}, crapi.ErrUnauthorized},
+		{"already exists", &crapi.APIError{HTTPStatus: 200, Status: crapi.StatusErrorAlreadyExist}, crapi.ErrAppAlreadyExists},
+		{"not found", &crapi.APIError{HTTPStatus: 200, Status: crapi.StatusErrorNotFound}, crapi.ErrAppNotFound},
+		{
+			name: "generic not found",
+			err:  &crapi.APIError{HTTPStatus: 200, Status: crapi.StatusErrorGeneric, Description: "App not found: missing"},
+			want: crapi.ErrAppNotFound,
+		},
+		{
+			name: "generic could not be found",
+			err:  &crapi.APIError{HTTPStatus: 200, Status: crapi.StatusErrorGeneric, Description: "App could not be found missing"},
+			want: crapi.ErrAppNotFound,
+		},
+	}
+
+	sentinels := []error{crapi.ErrUnauthorized, crapi.ErrAppAlreadyExists, crapi.ErrAppNotFound, crapi.ErrDecode}
+	for _, tt := range tests {
+		for _, sentinel := range sentinels {
+			if got := errors.Is(tt.err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("%s: errors.Is(%v) = %v", tt.name, sentinel, got)
+			}
+		}
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	_, err := crapi.NewCaproverInstance(srv.URL, "wrong")
+	if !errors.Is(err, crapi.ErrUnauthorized) {
+		t.Fatalf("got %v, want ErrUnauthorized", err)
+	}
+
+	var apiErr *crapi.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T, want *APIError", err)
+	}
+	if apiErr.Status != crapi.StatusWrongPassword || apiErr.Endpoint != crapi.URLLoginPath {
+		t.Errorf("got status %d on %s", apiErr.Status, apiErr.Endpoint)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	srv.FailNext(http.StatusBadGateway)
+
+	_, err := caprover.GetAppDetails()
+	if !errors.Is(err, crapi.ErrDecode) {
+		t.Fatalf("got %v, want ErrDecode", err)
+	}
+
+	var apiErr *crapi.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadGateway {
+		t.Errorf("got %#v, want http status 502", err)
+	}
+}
@@ -0,0 +1,56 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+)
+
+// SetHTTPAuth (appName string, user string, password string) error: This
+// method protects an application with HTTP basic auth. Caprover stores only a
+// hash of the password, which is never returned afterwards.
+func (c *Caprover) SetHTTPAuth(appName string, user string, password string) error {
+	return c.SetHTTPAuthContext(context.Background(), appName, user, password)
+}
+
+// SetHTTPAuthContext is like SetHTTPAuth but binds the request to the provided
+// context.
+func (c *Caprover) SetHTTPAuthContext(ctx context.Context, appName string, user string, password string) error {
+	if user == "" || password == "" {
+		return errors.New("crapi: http auth needs a user and a password")
+	}
+
+	c.log().Info("setting http auth", "app", appName, "user", user)
+
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	currentConfig.HTTPAuth = &HTTPAuth{
+		User:     user,
+		Password: password,
+	}
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// ClearHTTPAuth (appName string) error: This method removes the HTTP basic
+// auth protecting an application, if any.
+func (c *Caprover) ClearHTTPAuth(appName string) error {
+	return c.ClearHTTPAuthContext(context.Background(), appName)
+}
+
+// ClearHTTPAuthContext is like ClearHTTPAuth but binds the request to the
+// provided context.
+func (c *Caprover) ClearHTTPAuthContext(ctx context.Context, appName string) error {
+	c.log().Info("clearing http auth", "app", appName)
+
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	currentConfig.HTTPAuth = nil
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
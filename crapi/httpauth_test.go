@@ -0,0 +1,40 @@
+package crapi_test
+
+import "testing"
+
+func TestHTTPAuth(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.SetHTTPAuth("app", "admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	app, _ := srv.App("app")
+	if app.HTTPAuth == nil || app.HTTPAuth.User != "admin" || app.HTTPAuth.PasswordHashed == "" {
+		t.Fatalf("got http auth %+v", app.HTTPAuth)
+	}
+	hash := app.HTTPAuth.PasswordHashed
+
+	// Other updates send the definition back without the password, which
+	// must keep the stored hash.
+	if err := caprover.EnableWebsocketSupport("app"); err != nil {
+		t.Fatal(err)
+	}
+	if app, _ := srv.App("app"); app.HTTPAuth == nil || app.HTTPAuth.PasswordHashed != hash {
+		t.Errorf("got http auth %+v after another update", app.HTTPAuth)
+	}
+
+	if err := caprover.ClearHTTPAuth("app"); err != nil {
+		t.Fatal(err)
+	}
+	if app, _ := srv.App("app"); app.HTTPAuth != nil {
+		t.Errorf("got http auth %+v after clearing it", app.HTTPAuth)
+	}
+
+	if err := caprover.SetHTTPAuth("app", "admin", ""); err == nil {
+		t.Error("empty password accepted")
+	}
+}
@@ -0,0 +1,19 @@
+package crapi
+
+// Logger receives the progress messages and request traces of a Caprover
+// instance as a message followed by alternating key/value pairs. A
+// *slog.Logger satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// nopLogger discards everything. It is used when no logger was configured.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
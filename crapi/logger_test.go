@@ -0,0 +1,106 @@
+//go:build go1.21
+
+package crapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// appNotFound answers every request like Caprover does for an unknown app.
+func appNotFound(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, `{"status":%d,"description":"App not found","data":{}}`, crapi.StatusErrorGeneric)
+}
+
+func TestSilentByDefault(t *testing.T) {
+	srv := loginServer(t, appNotFound)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	caprover, err := crapi.NewCaproverInstance(srv.URL, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caprover.GetBuildLogs("app")
+	caprover.RemoveApp("app")
+
+	os.Stdout, os.Stderr = stdout, stderr
+	w.Close()
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != 0 {
+		t.Errorf("got output %q", output)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	srv := loginServer(t, appNotFound)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	caprover, err := crapi.NewCaproverInstance(srv.URL, "password", crapi.WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	caprover.GetBuildLogs("app")
+	caprover.ForceBuild("secret")
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("the webhook token was logged: %s", buf.String())
+	}
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+
+	find := func(msg string, path string) map[string]any {
+		for _, record := range records {
+			if record["msg"] == msg && (path == "" || record["path"] == path) {
+				return record
+			}
+		}
+		t.Fatalf("no %q record for %q in %v", msg, path, records)
+		return nil
+	}
+
+	if record := find("getting build logs", ""); record["app"] != "app" {
+		t.Errorf("got %v, want app=app", record)
+	}
+
+	record := find("caprover request", crapi.URLAppBuildLog+"/app/")
+	if record["level"] != "DEBUG" || record["method"] != "GET" {
+		t.Errorf("got %v", record)
+	}
+	if record["http_status"] != float64(http.StatusOK) || record["status"] != float64(crapi.StatusErrorGeneric) {
+		t.Errorf("got %v, want http_status 200 and status %d", record, crapi.StatusErrorGeneric)
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Errorf("got %v, want a latency", record)
+	}
+
+	find("caprover request", crapi.URLAppTriggerBuild)
+}
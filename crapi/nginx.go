@@ -0,0 +1,193 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GetNginxConfig (appName string) (string, error): This method retrieves the
+// nginx configuration template of an application. Apps without a custom
+// configuration use the default template of the Caprover instance, which is
+// returned instead.
+func (c *Caprover) GetNginxConfig(appName string) (string, error) {
+	return c.GetNginxConfigContext(context.Background(), appName)
+}
+
+// GetNginxConfigContext is like GetNginxConfig but binds the request to the
+// provided context.
+func (c *Caprover) GetNginxConfigContext(ctx context.Context, appName string) (string, error) {
+	allDetails, err := c.GetAppDetailsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, v := range allDetails.Data.AppDefinitions {
+		if v.AppName != appName {
+			continue
+		}
+		if v.CustomNginxConfig != "" {
+			return v.CustomNginxConfig, nil
+		}
+		return allDetails.Data.DefaultNginxConfig, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrAppNotFound, appName)
+}
+
+// SetNginxConfig (appName string, config string) error: This method sets a
+// custom nginx configuration template for an application, e.g. a copy of the
+// default template with a larger client_max_body_size. The template is
+// rendered by Caprover, see RenderNginxConfig to preview it.
+func (c *Caprover) SetNginxConfig(appName string, config string) error {
+	return c.SetNginxConfigContext(context.Background(), appName, config)
+}
+
+// SetNginxConfigContext is like SetNginxConfig but binds the request to the
+// provided context.
+func (c *Caprover) SetNginxConfigContext(ctx context.Context, appName string, config string) error {
+	c.log().Info("setting nginx config", "app", appName)
+
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	currentConfig.CustomNginxConfig = config
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// ResetNginxConfig (appName string) error: This method removes the custom
+// nginx configuration of an application, which then uses the default template
+// again.
+func (c *Caprover) ResetNginxConfig(appName string) error {
+	return c.ResetNginxConfigContext(context.Background(), appName)
+}
+
+// ResetNginxConfigContext is like ResetNginxConfig but binds the request to the
+// provided context.
+func (c *Caprover) ResetNginxConfigContext(ctx context.Context, appName string) error {
+	return c.SetNginxConfigContext(ctx, appName, "")
+}
+
+// NginxConfigValues returns the values Caprover passes to the nginx template of
+// an app as s, for use with RenderNginxConfig. Paths that only exist on the
+// Caprover host, such as the certificate paths, are left out.
+func NginxConfigValues(app AppDefinition, rootDomain string) map[string]any {
+	port := app.ContainerHTTPPort
+	if port == 0 {
+		port = 80
+	}
+
+	return map[string]any{
+		"publicDomain":      app.AppName + "." + rootDomain,
+		"hasSsl":            app.HasDefaultSubDomainSsl,
+		"forceSsl":          app.ForceSsl,
+		"websocketSupport":  app.WebsocketSupport,
+		"localDomain":       "srv-captain--" + app.AppName,
+		"containerHttpPort": port,
+		"httpBasicAuthPath": "",
+	}
+}
+
+var (
+	nginxOutputTag = regexp.MustCompile(`^[-=]\s*s\.(\w+)\s*$`)
+	nginxIfTag     = regexp.MustCompile(`^if\s*\(\s*(!?)\s*s\.(\w+)\s*\)\s*\{$`)
+	nginxElseTag   = regexp.MustCompile(`^\}\s*else\s*\{$`)
+)
+
+// RenderNginxConfig renders a Caprover nginx configuration template locally,
+// to preview what Caprover generates for an app. Only the subset of EJS used
+// by the Caprover templates is supported: <%-s.key%> and <%= s.key %>
+// placeholders and if (s.key) / if (!s.key) blocks with an optional else.
+// Missing keys render as empty and are false in conditions.
+func RenderNginxConfig(template string, values map[string]any) (string, error) {
+	var out strings.Builder
+
+	// active holds, for every open if block, whether its current branch is
+	// rendered.
+	var active []bool
+	rendering := func() bool {
+		for _, v := range active {
+			if !v {
+				return false
+			}
+		}
+		return true
+	}
+
+	rest := template
+	for {
+		start := strings.Index(rest, "<%")
+		if start < 0 {
+			break
+		}
+		if rendering() {
+			out.WriteString(rest[:start])
+		}
+		rest = rest[start+2:]
+
+		end := strings.Index(rest, "%>")
+		if end < 0 {
+			return "", errors.New("crapi: unterminated tag in nginx config template")
+		}
+		tag := strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(rest[:end], "-"), "_"))
+		rest = rest[end+2:]
+
+		placeholder := nginxOutputTag.FindStringSubmatch(tag)
+		condition := nginxIfTag.FindStringSubmatch(tag)
+
+		switch {
+		case strings.HasPrefix(tag, "#"):
+			// Comment.
+		case placeholder != nil:
+			if v := values[placeholder[1]]; v != nil && rendering() {
+				out.WriteString(fmt.Sprint(v))
+			}
+		case condition != nil:
+			active = append(active, isTruthy(values[condition[2]]) != (condition[1] == "!"))
+		case nginxElseTag.MatchString(tag):
+			if len(active) == 0 {
+				return "", errors.New("crapi: else without if in nginx config template")
+			}
+			active[len(active)-1] = !active[len(active)-1]
+		case tag == "}":
+			if len(active) == 0 {
+				return "", errors.New("crapi: unbalanced braces in nginx config template")
+			}
+			active = active[:len(active)-1]
+		default:
+			return "", fmt.Errorf("crapi: unsupported tag %q in nginx config template", tag)
+		}
+	}
+
+	if len(active) != 0 {
+		return "", errors.New("crapi: unclosed if block in nginx config template")
+	}
+	out.WriteString(rest)
+
+	return out.String(), nil
+}
+
+// isTruthy reports whether v is true in a JavaScript condition.
+func isTruthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case int:
+		return v != 0
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,116 @@
+package crapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+func TestRenderNginxConfig(t *testing.T) {
+	values := map[string]any{
+		"publicDomain": "app.captain.localhost",
+		"port":         3000,
+		"forceSsl":     false,
+		"websocket":    true,
+		"empty":        "",
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"server_name <%-s.publicDomain%>;", "server_name app.captain.localhost;"},
+		{"port <%= s.port %>", "port 3000"},
+		{"[<%-s.missing%>]", "[]"},
+		{"<% if (s.forceSsl) { %>https<% } else { %>http<% } %>", "http"},
+		{"<% if (!s.forceSsl) { %>plain<% } %>", "plain"},
+		{"<% if (s.empty) { %>set<% } else { %>unset<% } %>", "unset"},
+		{"<% if (s.websocket) { %>a<% if (s.forceSsl) { %>b<% } else { %>c<% } %>d<% } %>", "acd"},
+		{"<% if (s.forceSsl) { %><% if (s.websocket) { %>x<% } else { %>y<% } %><% } %>z", "z"},
+		{"<%# a comment %>text", "text"},
+	}
+
+	for _, tt := range tests {
+		got, err := crapi.RenderNginxConfig(tt.template, values)
+		if err != nil {
+			t.Errorf("RenderNginxConfig(%q): %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RenderNginxConfig(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestRenderNginxConfigErrors(t *testing.T) {
+	templates := []string{
+		"<%-s.publicDomain",
+		"<% if (s.forceSsl) { %>open",
+		"<% } %>",
+		"<% } else { %>",
+		"<% for (const v of s.list) { %>",
+		"<%- include('other') %>",
+	}
+
+	for _, template := range templates {
+		if _, err := crapi.RenderNginxConfig(template, nil); err == nil {
+			t.Errorf("RenderNginxConfig(%q) succeeded", template)
+		}
+	}
+}
+
+func TestNginxConfig(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := caprover.GetNginxConfig("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config != crapitest.DefaultNginxConfig {
+		t.Errorf("got %q, want the default config", config)
+	}
+
+	custom := strings.Replace(config, "500m", "2g", 1)
+	if err := caprover.SetNginxConfig("app", custom); err != nil {
+		t.Fatal(err)
+	}
+	if config, _ := caprover.GetNginxConfig("app"); config != custom {
+		t.Errorf("got %q, want the custom config", config)
+	}
+
+	if err := caprover.EnableWebsocketSupport("app"); err != nil {
+		t.Fatal(err)
+	}
+	app, err := caprover.GetAppDetailFor("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := crapi.RenderNginxConfig(custom, crapi.NginxConfigValues(app, crapitest.DefaultRootDomain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"server_name app.captain.localhost;",
+		"client_max_body_size 2g;",
+		"proxy_pass http://srv-captain--app:80;",
+		"proxy_set_header Upgrade $http_upgrade;",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered config lacks %q:\n%s", want, rendered)
+		}
+	}
+
+	if err := caprover.ResetNginxConfig("app"); err != nil {
+		t.Fatal(err)
+	}
+	if config, _ := caprover.GetNginxConfig("app"); config != crapitest.DefaultNginxConfig {
+		t.Errorf("got %q after reset, want the default config", config)
+	}
+}
@@ -0,0 +1,121 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Node types of a Docker Swarm cluster.
+const (
+	NodeTypeManager = "manager"
+	NodeTypeWorker  = "worker"
+)
+
+// ListNodes () ([]ClusterNode, error): This method retrieves the Docker Swarm
+// nodes of the Caprover cluster along with their role, state and resources.
+func (c *Caprover) ListNodes() ([]ClusterNode, error) {
+	return c.ListNodesContext(context.Background())
+}
+
+// ListNodesContext is like ListNodes but binds the request to the provided
+// context.
+func (c *Caprover) ListNodesContext(ctx context.Context) ([]ClusterNode, error) {
+	c.log().Info("listing nodes")
+
+	var rsp NodesResponse
+	if err := c.doRequest(ctx, "GET", URLSystemNodesPath, nil, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp.Data.Nodes, nil
+}
+
+// AddWorkerNode (node AddNodeRequest) error: This method joins a new server to
+// the Caprover cluster. Caprover connects to it over SSH with the given
+// private key and makes it join the swarm, which can take a while. A zero
+// NodeType defaults to NodeTypeWorker, SSHPort to 22 and SSHUser to root.
+func (c *Caprover) AddWorkerNode(node AddNodeRequest) error {
+	return c.AddWorkerNodeContext(context.Background(), node)
+}
+
+// AddWorkerNodeContext is like AddWorkerNode but binds the request to the
+// provided context.
+func (c *Caprover) AddWorkerNodeContext(ctx context.Context, node AddNodeRequest) error {
+	if node.RemoteNodeIPAddress == "" || node.CaptainIPAddress == "" || node.PrivateKey == "" {
+		return errors.New("crapi: adding a node needs its ip address, the captain ip address and a private key")
+	}
+	if node.NodeType == "" {
+		node.NodeType = NodeTypeWorker
+	}
+	if node.SSHPort == 0 {
+		node.SSHPort = 22
+	}
+	if node.SSHUser == "" {
+		node.SSHUser = "root"
+	}
+
+	c.log().Info("adding node", "type", node.NodeType, "ip", node.RemoteNodeIPAddress)
+
+	return c.doRequest(ctx, "POST", URLSystemNodesPath, node, nil)
+}
+
+// PinAppToNode (appName string, nodeID string) error: This method constrains
+// an application to run on the given node only, e.g. the one holding its
+// persistent data. It returns an error wrapping ErrNodeNotFound when the
+// cluster has no such node.
+func (c *Caprover) PinAppToNode(appName string, nodeID string) error {
+	return c.PinAppToNodeContext(context.Background(), appName, nodeID)
+}
+
+// PinAppToNodeContext is like PinAppToNode but binds the request to the
+// provided context.
+func (c *Caprover) PinAppToNodeContext(ctx context.Context, appName string, nodeID string) error {
+	nodes, err := c.ListNodesContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, v := range nodes {
+		if v.NodeID == nodeID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+	}
+
+	c.log().Info("pinning app to node", "app", appName, "node", nodeID)
+
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	currentConfig.NodeID = nodeID
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// UnpinApp (appName string) error: This method lets an application run on any
+// node of the cluster again.
+func (c *Caprover) UnpinApp(appName string) error {
+	return c.UnpinAppContext(context.Background(), appName)
+}
+
+// UnpinAppContext is like UnpinApp but binds the request to the provided
+// context.
+func (c *Caprover) UnpinAppContext(ctx context.Context, appName string) error {
+	c.log().Info("unpinning app", "app", appName)
+
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	currentConfig.NodeID = ""
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
@@ -0,0 +1,571 @@
+package crapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variables every one-click app template can use without declaring them.
+const (
+	OneClickAppNameVar    = "$$cap_appname"
+	OneClickRootDomainVar = "$$cap_root_domain"
+)
+
+// oneClickRandomHex matches the $$cap_gen_random_hex(N) generator, which may
+// appear in the default value of a template variable.
+var oneClickRandomHex = regexp.MustCompile(`\$\$cap_gen_random_hex\((\d+)\)`)
+
+// maxRandomHex is the longest $$cap_gen_random_hex(N) accepted, so that a
+// template can't make the client allocate an arbitrary amount of memory.
+const maxRandomHex = 256
+
+// TemplateValue is a string of a one-click app template. Templates are
+// written in YAML, where such values are often numbers or booleans, e.g.
+// containerHttpPort: 80, so these are accepted as well.
+type TemplateValue string
+
+// UnmarshalJSON accepts JSON strings, numbers and booleans.
+func (v *TemplateValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = TemplateValue(s)
+		return nil
+	}
+
+	var scalar any
+	if err := json.Unmarshal(data, &scalar); err != nil {
+		return err
+	}
+	switch scalar.(type) {
+	case nil:
+		*v = ""
+	case float64, bool:
+		*v = TemplateValue(strings.TrimSpace(string(data)))
+	default:
+		return fmt.Errorf("crapi: template value must be a string, number or boolean, got %s", data)
+	}
+	return nil
+}
+
+// OneClickAppTemplate is a one-click app template, in the version 4 format
+// where captainVersion is 4. Services are keyed by app name, which usually
+// contains OneClickAppNameVar until the template is resolved.
+type OneClickAppTemplate struct {
+	CaptainVersion      int                        `json:"captainVersion"`
+	Services            map[string]OneClickService `json:"services"`
+	CaproverOneClickApp OneClickAppInfo            `json:"caproverOneClickApp"`
+}
+
+// OneClickService describes a single app of a one-click app template, in a
+// subset of the Docker Compose format. Volumes are either name:/path, for a
+// named volume, or /host/path:/path, and ports are hostPort:containerPort.
+type OneClickService struct {
+	Image         string                   `json:"image"`
+	Environment   map[string]TemplateValue `json:"environment"`
+	Volumes       []string                 `json:"volumes"`
+	Ports         []string                 `json:"ports"`
+	DependsOn     []string                 `json:"depends_on"`
+	Restart       string                   `json:"restart"`
+	CaproverExtra OneClickServiceExtra     `json:"caproverExtra"`
+}
+
+// OneClickServiceExtra holds the Caprover specific settings of a service.
+type OneClickServiceExtra struct {
+	ContainerHTTPPort TemplateValue `json:"containerHttpPort"`
+	NotExposeAsWebApp TemplateValue `json:"notExposeAsWebApp"`
+	WebsocketSupport  TemplateValue `json:"websocketSupport"`
+	DockerfileLines   []string      `json:"dockerfileLines"`
+}
+
+// OneClickAppInfo holds the description and the variables of a one-click app
+// template.
+type OneClickAppInfo struct {
+	DisplayName   string             `json:"displayName"`
+	IsOfficial    bool               `json:"isOfficial"`
+	Description   string             `json:"description"`
+	Documentation string             `json:"documentation"`
+	Variables     []OneClickVariable `json:"variables"`
+	Instructions  struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"instructions"`
+}
+
+// OneClickVariable is a variable of a one-click app template, substituted
+// wherever its ID, e.g. $$cap_postgres_version, appears in the template.
+// ValidRegex is a JavaScript regular expression such as /^\d+$/.
+type OneClickVariable struct {
+	ID           string        `json:"id"`
+	Label        string        `json:"label"`
+	DefaultValue TemplateValue `json:"defaultValue"`
+	Description  string        `json:"description"`
+	ValidRegex   string        `json:"validRegex"`
+}
+
+// OneClickStep is a step of the deployment of a one-click app service.
+type OneClickStep int
+
+const (
+	// OneClickCreating means the app of the service is being created.
+	OneClickCreating OneClickStep = iota
+	// OneClickConfiguring means the environment variables, volumes, ports
+	// and other settings of the app are being set.
+	OneClickConfiguring
+	// OneClickDeploying means the image or Dockerfile of the service is being
+	// deployed.
+	OneClickDeploying
+	// OneClickDeployed means the deployment of the service was accepted. The
+	// build itself runs in the background.
+	OneClickDeployed
+	// OneClickFailed means the current step of the service failed.
+	OneClickFailed
+)
+
+func (s OneClickStep) String() string {
+	switch s {
+	case OneClickCreating:
+		return "creating"
+	case OneClickConfiguring:
+		return "configuring"
+	case OneClickDeploying:
+		return "deploying"
+	case OneClickDeployed:
+		return "deployed"
+	case OneClickFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// OneClickProgress is passed to OneClickDeployOptions.OnProgress whenever a
+// service of a one-click app moves to another step.
+type OneClickProgress struct {
+	// AppName is the name of the app of the service.
+	AppName string
+	Step    OneClickStep
+	// Err is the error the service failed with, when Step is OneClickFailed.
+	Err error
+}
+
+// OneClickDeployOptions controls DeployOneClickApp. The zero value deploys the
+// template with the default values of its variables.
+type OneClickDeployOptions struct {
+	// Values holds the values of the template variables, keyed by variable
+	// ID. Variables without a value use their default value, and IDs the
+	// template doesn't declare are rejected.
+	Values map[string]string
+	// OnProgress, if set, is called whenever a service moves to another step.
+	OnProgress func(progress OneClickProgress)
+}
+
+// ListOneClickApps () ([]OneClickApp, error): This method retrieves the
+// one-click apps of the repositories known to the Caprover instance.
+func (c *Caprover) ListOneClickApps() ([]OneClickApp, error) {
+	return c.ListOneClickAppsContext(context.Background())
+}
+
+// ListOneClickAppsContext is like ListOneClickApps but binds the request to
+// the provided context.
+func (c *Caprover) ListOneClickAppsContext(ctx context.Context) ([]OneClickApp, error) {
+	c.log().Info("listing one-click apps")
+
+	var rsp OneClickAppListResponse
+	if err := c.doRequest(ctx, "GET", URLOneClickAppListPath, nil, &rsp); err != nil {
+		return nil, err
+	}
+
+	return rsp.Data.OneClickApps, nil
+}
+
+// GetOneClickAppTemplate (name string, baseURL string) (OneClickAppTemplate,
+// error): This method retrieves the template of a one-click app, fetched by
+// Caprover from the repository at baseURL, the BaseURL of the app as listed by
+// ListOneClickApps. Only version 4 templates are supported.
+func (c *Caprover) GetOneClickAppTemplate(name string, baseURL string) (OneClickAppTemplate, error) {
+	return c.GetOneClickAppTemplateContext(context.Background(), name, baseURL)
+}
+
+// GetOneClickAppTemplateContext is like GetOneClickAppTemplate but binds the
+// request to the provided context.
+func (c *Caprover) GetOneClickAppTemplateContext(ctx context.Context, name string, baseURL string) (OneClickAppTemplate, error) {
+	c.log().Info("getting one-click app template", "name", name)
+
+	query := url.Values{}
+	query.Set("baseDomain", baseURL)
+	query.Set("appName", name)
+
+	var rsp OneClickAppTemplateResponse
+	if err := c.doRequest(ctx, "GET", URLOneClickAppTemplatePath+"?"+query.Encode(), nil, &rsp); err != nil {
+		return OneClickAppTemplate{}, err
+	}
+
+	if rsp.Data.AppTemplate.CaptainVersion != 4 {
+		return OneClickAppTemplate{}, fmt.Errorf("crapi: one-click app %s uses captainVersion %d, only 4 is supported", name, rsp.Data.AppTemplate.CaptainVersion)
+	}
+
+	return rsp.Data.AppTemplate, nil
+}
+
+// ResolveOneClickTemplate substitutes the variables of a one-click app
+// template: OneClickAppNameVar with appName, OneClickRootDomainVar with
+// rootDomain and every declared variable with its value from values or its
+// default value, in which $$cap_gen_random_hex(N) is replaced with N random
+// hex characters. Values must match the ValidRegex of their variable, unless
+// the regex uses JavaScript features Go doesn't support, such as lookaheads
+// or backreferences, in which case the value isn't validated. Values for
+// variables the template doesn't declare are rejected, to catch typos.
+func ResolveOneClickTemplate(template OneClickAppTemplate, appName string, rootDomain string, values map[string]string) (OneClickAppTemplate, error) {
+	if err := ValidateAppName(appName); err != nil {
+		return OneClickAppTemplate{}, err
+	}
+
+	declared := make(map[string]bool)
+	for _, v := range template.CaproverOneClickApp.Variables {
+		declared[v.ID] = true
+	}
+	unknown := make([]string, 0)
+	for id := range values {
+		if !declared[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return OneClickAppTemplate{}, fmt.Errorf("crapi: unknown one-click app variables %s", strings.Join(unknown, ", "))
+	}
+
+	resolved := map[string]string{
+		OneClickAppNameVar:    appName,
+		OneClickRootDomainVar: rootDomain,
+	}
+	for _, v := range template.CaproverOneClickApp.Variables {
+		value, ok := values[v.ID]
+		if !ok {
+			var err error
+			if value, err = expandRandomHex(string(v.DefaultValue)); err != nil {
+				return OneClickAppTemplate{}, err
+			}
+		}
+
+		if v.ValidRegex != "" {
+			pattern, err := compileJSRegex(v.ValidRegex)
+			if err == nil && !pattern.MatchString(value) {
+				return OneClickAppTemplate{}, fmt.Errorf("crapi: invalid value %q for variable %s (%s), must match %s", value, v.ID, v.Label, v.ValidRegex)
+			}
+		}
+
+		resolved[v.ID] = value
+	}
+
+	// Replace the longest ids first, so that a variable named like the
+	// beginning of another one doesn't break it.
+	ids := make([]string, 0, len(resolved))
+	for id := range resolved {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) > len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	pairs := make([]string, 0, 2*len(ids))
+	for _, id := range ids {
+		pairs = append(pairs, id, resolved[id])
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	// Substitute in the decoded template rather than its JSON, so that values
+	// don't need escaping and service names are substituted too.
+	content, err := json.Marshal(template)
+	if err != nil {
+		return OneClickAppTemplate{}, err
+	}
+	var tree any
+	if err := json.Unmarshal(content, &tree); err != nil {
+		return OneClickAppTemplate{}, err
+	}
+	if content, err = json.Marshal(replaceStrings(tree, replacer)); err != nil {
+		return OneClickAppTemplate{}, err
+	}
+
+	var result OneClickAppTemplate
+	if err := json.Unmarshal(content, &result); err != nil {
+		return OneClickAppTemplate{}, err
+	}
+
+	return result, nil
+}
+
+// DeployOneClickApp (appName string, template OneClickAppTemplate, opts
+// OneClickDeployOptions) error: This method resolves a one-click app template
+// with ResolveOneClickTemplate and deploys every service in it as an app:
+// first all the apps are created, then they are configured, then deployed, in
+// the order of their dependencies. Apps created before a failure are left in
+// place.
+func (c *Caprover) DeployOneClickApp(appName string, template OneClickAppTemplate, opts OneClickDeployOptions) error {
+	return c.DeployOneClickAppContext(context.Background(), appName, template, opts)
+}
+
+// DeployOneClickAppContext is like DeployOneClickApp but binds the requests to
+// the provided context.
+func (c *Caprover) DeployOneClickAppContext(ctx context.Context, appName string, template OneClickAppTemplate, opts OneClickDeployOptions) error {
+	c.log().Info("deploying one-click app", "app", appName)
+
+	allDetails, err := c.GetAppDetailsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range template.CaproverOneClickApp.Variables {
+		if v.ValidRegex == "" {
+			continue
+		}
+		if _, err := compileJSRegex(v.ValidRegex); err != nil {
+			c.log().Warn("not validating one-click app variable", "variable", v.ID, "regex", v.ValidRegex, "error", err)
+		}
+	}
+
+	resolved, err := ResolveOneClickTemplate(template, appName, allDetails.Data.RootDomain, opts.Values)
+	if err != nil {
+		return err
+	}
+
+	order, err := serviceOrder(resolved.Services)
+	if err != nil {
+		return err
+	}
+	for _, name := range order {
+		if err := ValidateAppName(name); err != nil {
+			return err
+		}
+	}
+
+	report := func(name string, step OneClickStep, err error) error {
+		if err != nil {
+			step = OneClickFailed
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(OneClickProgress{AppName: name, Step: step, Err: err})
+		}
+		return err
+	}
+
+	for _, name := range order {
+		report(name, OneClickCreating, nil)
+		service := resolved.Services[name]
+		if err := c.CreateAppContext(ctx, name, len(service.Volumes) > 0); err != nil {
+			return report(name, OneClickCreating, err)
+		}
+	}
+
+	for _, name := range order {
+		report(name, OneClickConfiguring, nil)
+		if err := c.configureOneClickService(ctx, name, resolved.Services[name]); err != nil {
+			return report(name, OneClickConfiguring, err)
+		}
+	}
+
+	for _, name := range order {
+		report(name, OneClickDeploying, nil)
+		if err := c.deployOneClickService(ctx, name, resolved.Services[name]); err != nil {
+			return report(name, OneClickDeploying, err)
+		}
+		report(name, OneClickDeployed, nil)
+	}
+
+	return nil
+}
+
+// configureOneClickService sets the environment variables, volumes, ports and
+// Caprover settings of the app of a resolved service.
+func (c *Caprover) configureOneClickService(ctx context.Context, appName string, service OneClickService) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(service.Environment))
+	for k := range service.Environment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	currentConfig.EnvVars = make([]EnvVarInformation, 0, len(keys))
+	for _, k := range keys {
+		currentConfig.EnvVars = append(currentConfig.EnvVars, EnvVarInformation{Key: k, Value: string(service.Environment[k])})
+	}
+
+	currentConfig.Volumes = make([]VolumeInformation, 0, len(service.Volumes))
+	for _, v := range service.Volumes {
+		source, containerPath, ok := strings.Cut(v, ":")
+		if !ok || source == "" || !strings.HasPrefix(containerPath, "/") {
+			return fmt.Errorf("crapi: invalid volume %q for %s", v, appName)
+		}
+		volume := VolumeInformation{ContainerPath: containerPath}
+		if strings.HasPrefix(source, "/") {
+			volume.HostPath = source
+		} else {
+			volume.VolumeName = source
+		}
+		currentConfig.Volumes = append(currentConfig.Volumes, volume)
+	}
+
+	currentConfig.Ports = make([]PortInformation, 0, len(service.Ports))
+	for _, p := range service.Ports {
+		host, container, _ := strings.Cut(p, ":")
+		hostPort, err1 := strconv.Atoi(host)
+		containerPort, err2 := strconv.Atoi(container)
+		if err1 != nil || err2 != nil || !isValidPort(hostPort) || !isValidPort(containerPort) {
+			return fmt.Errorf("crapi: invalid port mapping %q for %s", p, appName)
+		}
+		currentConfig.Ports = append(currentConfig.Ports, PortInformation{HostPort: hostPort, ContainerPort: containerPort})
+	}
+
+	extra := service.CaproverExtra
+	if extra.ContainerHTTPPort != "" {
+		port, err := strconv.Atoi(string(extra.ContainerHTTPPort))
+		if err != nil || !isValidPort(port) {
+			return fmt.Errorf("crapi: invalid container http port %q for %s", extra.ContainerHTTPPort, appName)
+		}
+		currentConfig.ContainerHTTPPort = port
+	}
+	currentConfig.NotExposeAsWebApp = extra.NotExposeAsWebApp == "true"
+	currentConfig.WebsocketSupport = extra.WebsocketSupport == "true"
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// deployOneClickService deploys the image or Dockerfile of a resolved service.
+func (c *Caprover) deployOneClickService(ctx context.Context, appName string, service OneClickService) error {
+	if len(service.CaproverExtra.DockerfileLines) > 0 {
+		return c.DeployCaptainDefinitionContext(ctx, appName, CaptainDefinition{
+			DockerfileLines: service.CaproverExtra.DockerfileLines,
+		})
+	}
+	if service.Image == "" {
+		return fmt.Errorf("crapi: service %s has neither an image nor dockerfile lines", appName)
+	}
+
+	return c.DeployImageContext(ctx, appName, service.Image)
+}
+
+// serviceOrder sorts the services of a template so that every service comes
+// after the ones it depends on, and by name otherwise.
+func serviceOrder(services map[string]OneClickService) ([]string, error) {
+	if len(services) == 0 {
+		return nil, errors.New("crapi: one-click app template has no services")
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	order := make([]string, 0, len(names))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("crapi: one-click app services have a dependency cycle through %s", name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dep := range services[name].DependsOn {
+			if _, ok := services[dep]; !ok {
+				return fmt.Errorf("crapi: service %s depends on unknown service %s", name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// replaceStrings applies the replacer to every string and map key of a
+// decoded JSON value.
+func replaceStrings(v any, r *strings.Replacer) any {
+	switch v := v.(type) {
+	case string:
+		return r.Replace(v)
+	case []any:
+		for i := range v {
+			v[i] = replaceStrings(v[i], r)
+		}
+		return v
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[r.Replace(k)] = replaceStrings(e, r)
+		}
+		return m
+	}
+	return v
+}
+
+// expandRandomHex replaces every $$cap_gen_random_hex(N) of s with N random
+// hex characters. N must be between 1 and maxRandomHex.
+func expandRandomHex(s string) (string, error) {
+	var err error
+	s = oneClickRandomHex.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		n, e := strconv.Atoi(oneClickRandomHex.FindStringSubmatch(m)[1])
+		if e != nil || n < 1 || n > maxRandomHex {
+			err = fmt.Errorf("crapi: invalid %s, the length must be between 1 and %d", m, maxRandomHex)
+			return m
+		}
+		buf := make([]byte, (n+1)/2)
+		if _, e := rand.Read(buf); e != nil {
+			err = e
+			return m
+		}
+		return hex.EncodeToString(buf)[:n]
+	})
+	return s, err
+}
+
+// compileJSRegex compiles a JavaScript regular expression literal such as
+// /^\d+$/i. A pattern without slashes is compiled as is.
+func compileJSRegex(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "/") {
+		if end := strings.LastIndex(pattern, "/"); end > 0 {
+			flags := pattern[end+1:]
+			pattern = pattern[1:end]
+			if strings.Contains(flags, "i") {
+				pattern = "(?i)" + pattern
+			}
+		}
+	}
+
+	return regexp.Compile(pattern)
+}
@@ -0,0 +1,259 @@
+package crapi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// wordpressTemplate is a trimmed down version of the WordPress one-click app,
+// as served by Caprover once converted from YAML.
+const wordpressTemplate = `{
+	"captainVersion": 4,
+	"services": {
+		"$$cap_appname-db": {
+			"image": "mysql:$$cap_db_version",
+			"volumes": ["$$cap_appname-db-data:/var/lib/mysql"],
+			"environment": {
+				"MYSQL_ROOT_PASSWORD": "$$cap_db_pass",
+				"MYSQL_DATABASE": "wordpress"
+			},
+			"caproverExtra": {"notExposeAsWebApp": true}
+		},
+		"$$cap_appname-wordpress": {
+			"image": "wordpress:latest",
+			"depends_on": ["$$cap_appname-db"],
+			"volumes": ["$$cap_appname-wp-data:/var/www/html"],
+			"environment": {
+				"WORDPRESS_DB_HOST": "srv-captain--$$cap_appname-db:3306",
+				"WORDPRESS_DB_PASSWORD": "$$cap_db_pass",
+				"WORDPRESS_URL": "http://$$cap_appname-wordpress.$$cap_root_domain"
+			},
+			"caproverExtra": {"containerHttpPort": 80}
+		}
+	},
+	"caproverOneClickApp": {
+		"displayName": "WordPress",
+		"isOfficial": true,
+		"variables": [
+			{
+				"id": "$$cap_db_version",
+				"label": "MySQL Version",
+				"defaultValue": 8,
+				"validRegex": "/^([^\\s^\\/])+$/"
+			},
+			{
+				"id": "$$cap_db_pass",
+				"label": "Database Password",
+				"defaultValue": "$$cap_gen_random_hex(16)",
+				"validRegex": "/.{8,}/"
+			}
+		]
+	}
+}`
+
+func parseTemplate(t *testing.T, content string) crapi.OneClickAppTemplate {
+	t.Helper()
+
+	var template crapi.OneClickAppTemplate
+	if err := json.Unmarshal([]byte(content), &template); err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+func TestResolveOneClickTemplate(t *testing.T) {
+	template := parseTemplate(t, wordpressTemplate)
+
+	resolved, err := crapi.ResolveOneClickTemplate(template, "blog", "example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, ok := resolved.Services["blog-db"]
+	if !ok {
+		t.Fatalf("got services %+v", resolved.Services)
+	}
+	if db.Image != "mysql:8" || db.CaproverExtra.NotExposeAsWebApp != "true" {
+		t.Errorf("got db service %+v", db)
+	}
+
+	password := string(db.Environment["MYSQL_ROOT_PASSWORD"])
+	if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(password) {
+		t.Errorf("got generated password %q", password)
+	}
+
+	wp := resolved.Services["blog-wordpress"]
+	if string(wp.Environment["WORDPRESS_DB_PASSWORD"]) != password {
+		t.Error("the generated password differs between services")
+	}
+	if got := string(wp.Environment["WORDPRESS_URL"]); got != "http://blog-wordpress.example.com" {
+		t.Errorf("got url %q", got)
+	}
+	if !reflect.DeepEqual(wp.DependsOn, []string{"blog-db"}) {
+		t.Errorf("got depends_on %v", wp.DependsOn)
+	}
+}
+
+func TestResolveOneClickTemplateValues(t *testing.T) {
+	template := parseTemplate(t, wordpressTemplate)
+
+	resolved, err := crapi.ResolveOneClickTemplate(template, "blog", "example.com", map[string]string{
+		"$$cap_db_version": "5.7",
+		"$$cap_db_pass":    `pa"ss\word`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := resolved.Services["blog-db"]
+	if db.Image != "mysql:5.7" || db.Environment["MYSQL_ROOT_PASSWORD"] != `pa"ss\word` {
+		t.Errorf("got db service %+v", db)
+	}
+
+	tests := map[string]map[string]string{
+		"invalid value":    {"$$cap_db_pass": "short"},
+		"unknown variable": {"$$cap_db_passwd": "long enough"},
+	}
+	for name, values := range tests {
+		if _, err := crapi.ResolveOneClickTemplate(template, "blog", "example.com", values); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, err := crapi.ResolveOneClickTemplate(template, "Blog", "example.com", nil); err == nil {
+		t.Error("invalid app name accepted")
+	}
+}
+
+func TestResolveOneClickTemplateUnsupportedRegex(t *testing.T) {
+	template := parseTemplate(t, wordpressTemplate)
+	// Lookaheads are valid in JavaScript but not in Go, so the value can't be
+	// validated.
+	template.CaproverOneClickApp.Variables[1].ValidRegex = `/^(?=.*\d).{8,}$/`
+
+	if _, err := crapi.ResolveOneClickTemplate(template, "blog", "example.com", map[string]string{"$$cap_db_pass": "x"}); err != nil {
+		t.Errorf("got %v, want the value not to be validated", err)
+	}
+}
+
+func TestResolveOneClickTemplateRandomHexLength(t *testing.T) {
+	for _, n := range []string{"0", "257", "100000", "99999999999999999999"} {
+		template := parseTemplate(t, wordpressTemplate)
+		template.CaproverOneClickApp.Variables[1].DefaultValue = crapi.TemplateValue("$$cap_gen_random_hex(" + n + ")")
+
+		if _, err := crapi.ResolveOneClickTemplate(template, "blog", "example.com", nil); err == nil {
+			t.Errorf("$$cap_gen_random_hex(%s) accepted", n)
+		}
+	}
+
+	template := parseTemplate(t, wordpressTemplate)
+	template.CaproverOneClickApp.Variables[1].DefaultValue = "$$cap_gen_random_hex(256)"
+
+	resolved, err := crapi.ResolveOneClickTemplate(template, "blog", "example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(resolved.Services["blog-db"].Environment["MYSQL_ROOT_PASSWORD"]); got != 256 {
+		t.Errorf("got a password of %d characters, want 256", got)
+	}
+}
+
+func TestDeployOneClickApp(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	err := srv.AddOneClickApp(crapi.OneClickApp{
+		Name:        "wordpress",
+		DisplayName: "WordPress",
+		BaseURL:     "https://oneclickapps.caprover.com",
+	}, wordpressTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps, err := caprover.ListOneClickApps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 1 || apps[0].Name != "wordpress" {
+		t.Fatalf("got %+v", apps)
+	}
+
+	template, err := caprover.GetOneClickAppTemplate(apps[0].Name, apps[0].BaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []string
+	err = caprover.DeployOneClickApp("blog", template, crapi.OneClickDeployOptions{
+		Values: map[string]string{"$$cap_db_pass": "correct horse"},
+		OnProgress: func(p crapi.OneClickProgress) {
+			progress = append(progress, p.AppName+" "+p.Step.String())
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"blog-db creating",
+		"blog-wordpress creating",
+		"blog-db configuring",
+		"blog-wordpress configuring",
+		"blog-db deploying",
+		"blog-db deployed",
+		"blog-wordpress deploying",
+		"blog-wordpress deployed",
+	}
+	if !reflect.DeepEqual(progress, want) {
+		t.Errorf("got progress\n%s\nwant\n%s", strings.Join(progress, "\n"), strings.Join(want, "\n"))
+	}
+
+	db, _ := srv.App("blog-db")
+	if !db.HasPersistentData || !db.NotExposeAsWebApp || len(db.Volumes) != 1 || db.Volumes[0].VolumeName != "blog-db-data" {
+		t.Errorf("got db app %+v", db)
+	}
+	wp, _ := srv.App("blog-wordpress")
+	wantEnv := crapi.EnvVarInformation{Key: "WORDPRESS_URL", Value: "http://blog-wordpress.captain.localhost"}
+	if len(wp.EnvVars) != 3 || wp.EnvVars[2] != wantEnv {
+		t.Errorf("got wordpress env vars %+v", wp.EnvVars)
+	}
+	if len(wp.Versions) != 1 || wp.Versions[0].DeployedImageName != "wordpress:latest" {
+		t.Errorf("got wordpress versions %+v", wp.Versions)
+	}
+}
+
+func TestDeployOneClickAppFailure(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("blog-wordpress", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var last crapi.OneClickProgress
+	err := caprover.DeployOneClickApp("blog", parseTemplate(t, wordpressTemplate), crapi.OneClickDeployOptions{
+		OnProgress: func(p crapi.OneClickProgress) { last = p },
+	})
+	if err == nil {
+		t.Fatal("deployed over an existing app")
+	}
+	if last.AppName != "blog-wordpress" || last.Step != crapi.OneClickFailed || last.Err == nil {
+		t.Errorf("got last progress %+v", last)
+	}
+}
+
+func TestGetOneClickAppTemplateVersion(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	err := srv.AddOneClickApp(crapi.OneClickApp{Name: "old", BaseURL: "https://example.com"}, `{"captainVersion": 2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := caprover.GetOneClickAppTemplate("old", "https://example.com"); err == nil {
+		t.Error("version 2 template accepted")
+	}
+}
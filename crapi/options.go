@@ -0,0 +1,126 @@
+package crapi
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Option configures a Caprover instance created by NewCaproverInstance.
+type Option func(*options)
+
+// options collects the values set by the Option functions before the http
+// client used by the Caprover instance is assembled.
+type options struct {
+	httpClient  *http.Client
+	timeout     time.Duration
+	tlsConfig   *tls.Config
+	logger      Logger
+	retryPolicy RetryPolicy
+	otpProvider OTPProvider
+	tokenCache  TokenCache
+}
+
+// WithHTTPClient makes the Caprover instance send every request through the
+// given client. The client is copied, so later WithTimeout or WithTLSConfig
+// options never modify the value passed in.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithTimeout bounds every request, including reading the response body, by
+// the given duration.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used when talking to the Caprover
+// instance. Use it to trust a custom CA bundle for self-signed dashboards or
+// to present a client certificate for mTLS.
+//
+// The configuration only applies to the default transport or to an
+// *http.Transport given with WithHTTPClient. A client with any other
+// http.RoundTripper, e.g. an instrumentation wrapper, is used as is and TLS
+// must then be configured on that transport.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithLogger makes the Caprover instance report progress and a trace of every
+// request, with its path, status and latency, to the given logger. Nothing is
+// logged by default.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithRetryPolicy makes the Caprover instance retry transient failures of
+// idempotent requests according to the given policy. Requests are not retried
+// by default.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithOTP sends the given one-time password with the initial login, for
+// Caprover instances with two-factor authentication enabled. As the code
+// expires quickly, prefer WithOTPProvider for long-lived instances that may
+// have to log in again.
+func WithOTP(code string) Option {
+	return WithOTPProvider(StaticOTP(code))
+}
+
+// WithOTPProvider asks the given provider for a one-time password on every
+// login, for Caprover instances with two-factor authentication enabled. Use
+// TOTPProvider to generate the codes from the two-factor secret.
+func WithOTPProvider(provider OTPProvider) Option {
+	return func(o *options) {
+		o.otpProvider = provider
+	}
+}
+
+// WithTokenCache makes the Caprover instance reuse the token stored in the
+// cache for its endpoint instead of logging in, and store every new token
+// obtained by logging in. A cached token that has expired is replaced
+// transparently on the first request.
+func WithTokenCache(cache TokenCache) Option {
+	return func(o *options) {
+		o.tokenCache = cache
+	}
+}
+
+// buildHTTPClient assembles the http client described by the options.
+func (o *options) buildHTTPClient() *http.Client {
+	client := &http.Client{}
+	if o.httpClient != nil {
+		*client = *o.httpClient
+	}
+
+	if o.tlsConfig != nil {
+		// Custom round trippers are left alone, they own their TLS setup.
+		switch t := client.Transport.(type) {
+		case nil:
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = o.tlsConfig
+			client.Transport = transport
+		case *http.Transport:
+			transport := t.Clone()
+			transport.TLSClientConfig = o.tlsConfig
+			client.Transport = transport
+		}
+	}
+
+	if o.timeout > 0 {
+		client.Timeout = o.timeout
+	}
+
+	return client
+}
@@ -0,0 +1,128 @@
+package crapi_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// countingTransport counts the requests going through it.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// emptyAppList answers every request with an empty app list.
+func emptyAppList(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"status":100,"description":"","data":{"appDefinitions":[],"rootDomain":"captain.localhost"}}`)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	srv := loginServer(t, func(w http.ResponseWriter, r *http.Request) {
+		emptyAppList(w)
+	})
+
+	transport := &countingTransport{}
+	caprover, err := crapi.NewCaproverInstance(srv.URL, "password",
+		crapi.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := transport.requests.Load(); n != 2 {
+		t.Errorf("got %d requests through the custom client, want 2", n)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	// The headers arrive in time, the rest of the body never does.
+	srv := loginServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"status":100,"data":`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	caprover, err := crapi.NewCaproverInstance(srv.URL, "password", crapi.WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = caprover.GetAppDetails()
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("got %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v", elapsed)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == crapi.URLLoginPath {
+			io.WriteString(w, `{"status":100,"description":"","data":{"token":"token"}}`)
+			return
+		}
+		emptyAppList(w)
+	}))
+	// The handshake rejected by the untrusted client is expected.
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	if _, err := crapi.NewCaproverInstance(ts.URL, "password"); err == nil {
+		t.Fatal("self-signed certificate accepted without a TLS config")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+
+	trusted, err := crapi.NewCaproverInstance(ts.URL, "password",
+		crapi.WithTLSConfig(&tls.Config{RootCAs: pool}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trusted.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTLSConfigKeepsCustomTransport(t *testing.T) {
+	srv, _ := newClient(t)
+
+	transport := &countingTransport{}
+	caprover, err := srv.Client(
+		crapi.WithHTTPClient(&http.Client{Transport: transport}),
+		crapi.WithTLSConfig(&tls.Config{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := transport.requests.Load(); n != 2 {
+		t.Errorf("got %d requests through the custom transport, want 2", n)
+	}
+}
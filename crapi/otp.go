@@ -0,0 +1,72 @@
+package crapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OTPProvider returns the one-time password sent along with the dashboard
+// password when logging in to a Caprover instance with two-factor
+// authentication enabled. It is called for the initial login and for every
+// re-login, so it must return a fresh code each time.
+type OTPProvider func(ctx context.Context) (string, error)
+
+// StaticOTP returns an OTPProvider that always returns the given code. It is
+// only suitable for a single, immediate login.
+func StaticOTP(code string) OTPProvider {
+	return func(context.Context) (string, error) {
+		return code, nil
+	}
+}
+
+// TOTPProvider returns an OTPProvider generating RFC 6238 codes, as shown by
+// authenticator apps, from the base32 secret displayed by the Caprover
+// dashboard when two-factor authentication was enabled.
+func TOTPProvider(secret string) (OTPProvider, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(context.Context) (string, error) {
+		return totpCode(key, time.Now()), nil
+	}, nil
+}
+
+// decodeTOTPSecret decodes a base32 secret, ignoring case, spaces and padding.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("crapi: invalid totp secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("crapi: invalid totp secret: empty")
+	}
+
+	return key, nil
+}
+
+// totpCode computes the 6 digit code for the 30 second time step containing t
+// using HMAC-SHA1, the defaults used by Caprover.
+func totpCode(key []byte, t time.Time) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/30))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", code%1000000)
+}
@@ -0,0 +1,139 @@
+package crapi_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+func TestOTPRequired(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	srv.RequireOTP("123456")
+
+	if _, err := srv.Client(); !errors.Is(err, crapi.ErrOTPRequired) {
+		t.Errorf("without otp: got %v, want ErrOTPRequired", err)
+	}
+
+	_, err := srv.Client(crapi.WithOTP("654321"))
+	if !errors.Is(err, crapi.ErrUnauthorized) || errors.Is(err, crapi.ErrOTPRequired) {
+		t.Errorf("wrong otp: got %v, want ErrUnauthorized", err)
+	}
+
+	if _, err := srv.Client(crapi.WithOTP("123456")); err != nil {
+		t.Errorf("right otp: %v", err)
+	}
+}
+
+func TestOTPProviderOnRelogin(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	srv.RequireOTP("111111")
+
+	calls := 0
+	provider := func(context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "111111", nil
+		}
+		return "222222", nil
+	}
+
+	caprover, err := srv.Client(crapi.WithOTPProvider(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.RequireOTP("222222")
+	srv.ExpireTokens()
+
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+}
+
+// referenceTOTP computes an RFC 6238 code independently of the crapi package.
+func referenceTOTP(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[19] & 0x0f
+	code := (uint32(sum[offset])&0x7f)<<24 |
+		uint32(sum[offset+1])<<16 |
+		uint32(sum[offset+2])<<8 |
+		uint32(sum[offset+3])
+	return fmt.Sprintf("%06d", code%1000000)
+}
+
+func TestTOTPProvider(t *testing.T) {
+	const secret = "JBSWY3DPEHPK3PXP"
+
+	// The dashboard may show the secret in lower case, grouped by spaces.
+	provider, err := crapi.TOTPProvider("jbsw y3dp ehpk 3pxp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now()
+	code, err := provider(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The time step may change between the two clock readings.
+	if code != referenceTOTP(t, secret, before) && code != referenceTOTP(t, secret, time.Now()) {
+		t.Errorf("got code %s", code)
+	}
+
+	for _, secret := range []string{"", "not base32!"} {
+		if _, err := crapi.TOTPProvider(secret); err == nil {
+			t.Errorf("TOTPProvider(%q) accepted", secret)
+		}
+	}
+}
+
+func TestTOTPLogin(t *testing.T) {
+	const secret = "JBSWY3DPEHPK3PXP"
+
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	srv.RequireOTP(referenceTOTP(t, secret, time.Now()))
+
+	provider, err := crapi.TOTPProvider(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Retry once in case the time step changed in between.
+	if _, err := srv.Client(crapi.WithOTPProvider(provider)); err != nil {
+		srv.RequireOTP(referenceTOTP(t, secret, time.Now()))
+		if _, err := srv.Client(crapi.WithOTPProvider(provider)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
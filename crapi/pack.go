@@ -0,0 +1,314 @@
+package crapi
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CaptainDefinitionFile is the name of the file describing how Caprover
+// builds an app, which must be at the root of a deployed source tree.
+const CaptainDefinitionFile = "captain-definition"
+
+// PackSourceDirectory writes the source tree rooted at dir to w as a gzipped
+// tar, ready for DeployTarball. Files matched by the .gitignore and
+// .dockerignore files at the root of dir are left out, as is the .git
+// directory. It fails when dir has no valid captain-definition file.
+func PackSourceDirectory(dir string, w io.Writer) error {
+	definition, err := os.ReadFile(filepath.Join(dir, CaptainDefinitionFile))
+	if err != nil {
+		return fmt.Errorf("crapi: %s is required to deploy a directory: %w", CaptainDefinitionFile, err)
+	}
+	if !json.Valid(definition) {
+		return fmt.Errorf("crapi: %s is not valid JSON", CaptainDefinitionFile)
+	}
+
+	var rules []ignoreRule
+	for _, name := range []string{".gitignore", ".dockerignore"} {
+		fileRules, err := readIgnoreFile(filepath.Join(dir, name), name == ".dockerignore")
+		if err != nil {
+			return err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel == ".git" {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isIgnored(rules, rel, entry.IsDir()) {
+			// An ignored directory is still walked when an exception such as
+			// !node_modules/keep may include something inside it.
+			if entry.IsDir() && !mayIncludeWithin(rules, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		return addToTar(tw, file, rel, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addToTar writes a single directory, regular file or symlink to the tar.
+func addToTar(tw *tar.Writer, file string, name string, entry fs.DirEntry) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+
+	link := ""
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if link, err = os.Readlink(file); err != nil {
+			return err
+		}
+	} else if !info.Mode().IsRegular() && !info.IsDir() {
+		// Sockets, devices and the like can't be part of a build context.
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ignoreRule is a single pattern of a .gitignore or .dockerignore file.
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	docker   bool
+}
+
+// readIgnoreFile parses the ignore file at name, which may not exist. Patterns
+// of a .dockerignore file are always relative to the root, while those of a
+// .gitignore file without a slash match at any depth.
+func readIgnoreFile(name string, docker bool) ([]ignoreRule, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{anchored: docker, docker: docker}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+		}
+		rule.pattern = path.Clean(strings.TrimPrefix(line, "/"))
+		if rule.pattern == "." {
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// isIgnored reports whether the slash separated path relative to the root is
+// ignored. As in Docker, a rule matching a parent directory matches everything
+// inside it, and the last matching rule wins. As in Git, an exception of the
+// .gitignore file can't include a path whose parent directory it excludes.
+func isIgnored(rules []ignoreRule, rel string, isDir bool) bool {
+	if rel == CaptainDefinitionFile {
+		return false
+	}
+
+	parentExcluded := gitExcludesParent(rules, rel)
+	ignored := false
+	for _, rule := range rules {
+		if rule.negate && !rule.docker && parentExcluded {
+			continue
+		}
+		if ruleMatches(rule, rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// ruleMatches reports whether the rule matches the path or one of its parent
+// directories.
+func ruleMatches(rule ignoreRule, rel string, isDir bool) bool {
+	segments := strings.Split(rel, "/")
+	for i := 1; i <= len(segments); i++ {
+		if rule.dirOnly && i == len(segments) && !isDir {
+			continue
+		}
+
+		var matched bool
+		if rule.anchored {
+			matched = matchSegments(strings.Split(rule.pattern, "/"), segments[:i])
+		} else {
+			matched, _ = path.Match(rule.pattern, segments[i-1])
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gitExcludesParent reports whether the .gitignore rules exclude a parent
+// directory of the slash separated path rel.
+func gitExcludesParent(rules []ignoreRule, rel string) bool {
+	segments := strings.Split(rel, "/")
+	for i := 1; i < len(segments); i++ {
+		dir := strings.Join(segments[:i], "/")
+
+		excluded := false
+		for _, rule := range rules {
+			if !rule.docker && ruleMatches(rule, dir, true) {
+				excluded = !rule.negate
+			}
+		}
+		if excluded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mayIncludeWithin reports whether an exception rule of the .dockerignore
+// file may match something inside the directory dir. Exceptions of the
+// .gitignore file never do, Git doesn't look inside excluded directories.
+func mayIncludeWithin(rules []ignoreRule, dir string) bool {
+	for _, rule := range rules {
+		if !rule.negate || !rule.docker {
+			continue
+		}
+		if !rule.anchored || matchPrefix(strings.Split(rule.pattern, "/"), strings.Split(dir, "/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPrefix reports whether the pattern segments may match a path below the
+// given directory segments.
+func matchPrefix(pattern []string, segments []string) bool {
+	for len(segments) > 0 {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(pattern) > 0
+}
+
+// matchSegments matches path segments against pattern segments, where a "**"
+// segment matches any number of path segments.
+func matchSegments(pattern []string, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
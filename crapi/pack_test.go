@@ -0,0 +1,169 @@
+package crapi_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// writeTree creates the given files, keyed by slash separated path, in dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// packedFiles packs dir and returns the sorted names of the regular files in
+// the resulting tarball.
+func packedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := crapi.PackSourceDirectory(dir, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func TestPackSourceDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"captain-definition":          `{"schemaVersion":2,"dockerfilePath":"./Dockerfile"}`,
+		"Dockerfile":                  "FROM scratch",
+		".gitignore":                  "build/\nsecret.txt\n*.log\n!keep.log\n",
+		".dockerignore":               "node_modules\n!node_modules/keep\ndocs/**/*.md\n",
+		"main.go":                     "package main",
+		"debug.log":                   "",
+		"keep.log":                    "",
+		"sub/secret.txt":              "",
+		"sub/main.go":                 "",
+		"build/out.bin":               "",
+		"node_modules/keep/index.js":  "",
+		"node_modules/other/index.js": "",
+		"docs/guide/intro.md":         "",
+		"docs/guide/diagram.png":      "",
+		".git/HEAD":                   "ref: refs/heads/main",
+	})
+
+	want := []string{
+		".dockerignore",
+		".gitignore",
+		"Dockerfile",
+		"captain-definition",
+		"docs/guide/diagram.png",
+		"keep.log",
+		"main.go",
+		"node_modules/keep/index.js",
+		"sub/main.go",
+	}
+	if got := packedFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("packed %v\nwant %v", got, want)
+	}
+}
+
+func TestPackGitignoreExceptionInIgnoredDirectory(t *testing.T) {
+	// Git doesn't look inside an excluded directory, so its exceptions can't
+	// include anything there, unlike those of a .dockerignore file.
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"captain-definition": `{"schemaVersion":2,"imageName":"nginx"}`,
+		".gitignore":         "build/\n!build/keep\ndist/\n!dist/keep\n",
+		".dockerignore":      "!dist/other\n",
+		"build/keep":         "",
+		"build/out.bin":      "",
+		"dist/keep":          "",
+		"dist/other":         "",
+	})
+
+	want := []string{".dockerignore", ".gitignore", "captain-definition", "dist/other"}
+	if got := packedFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("packed %v, want %v", got, want)
+	}
+}
+
+func TestPackKeepsCaptainDefinition(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"captain-definition": `{"schemaVersion":2,"imageName":"nginx"}`,
+		".dockerignore":      "*\n",
+		"index.html":         "",
+	})
+
+	want := []string{"captain-definition"}
+	if got := packedFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("packed %v, want %v", got, want)
+	}
+}
+
+func TestPackGitFile(t *testing.T) {
+	// In a git worktree .git is a file, which is left out like the directory.
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"captain-definition": `{"schemaVersion":2,"imageName":"nginx"}`,
+		".git":               "gitdir: ../repo/.git/worktrees/app",
+		"index.html":         "",
+	})
+
+	want := []string{"captain-definition", "index.html"}
+	if got := packedFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("packed %v, want %v", got, want)
+	}
+}
+
+func TestPackRequiresCaptainDefinition(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing": {"index.html": ""},
+		"invalid": {"captain-definition": "{not json"},
+	}
+
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, files)
+
+			err := crapi.PackSourceDirectory(dir, io.Discard)
+			if err == nil || !strings.Contains(err.Error(), crapi.CaptainDefinitionFile) {
+				t.Errorf("got %v, want an error about %s", err, crapi.CaptainDefinitionFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package crapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListPortMappings (appName string) ([]PortInformation, error): This method
+// retrieves the host ports published by an application.
+func (c *Caprover) ListPortMappings(appName string) ([]PortInformation, error) {
+	return c.ListPortMappingsContext(context.Background(), appName)
+}
+
+// ListPortMappingsContext is like ListPortMappings but binds the request to the
+// provided context.
+func (c *Caprover) ListPortMappingsContext(ctx context.Context, appName string) ([]PortInformation, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.Ports, nil
+}
+
+// AddPortMapping (appName string, hostPort int, containerPort int) error: This
+// method publishes a container port of an application on a port of the host,
+// e.g. for a TCP service such as an MQTT broker. It returns an error wrapping
+// ErrPortInUse when the host port is already published by any application.
+func (c *Caprover) AddPortMapping(appName string, hostPort int, containerPort int) error {
+	return c.AddPortMappingContext(context.Background(), appName, hostPort, containerPort)
+}
+
+// AddPortMappingContext is like AddPortMapping but binds the request to the
+// provided context.
+func (c *Caprover) AddPortMappingContext(ctx context.Context, appName string, hostPort int, containerPort int) error {
+	if !isValidPort(hostPort) || !isValidPort(containerPort) {
+		return fmt.Errorf("crapi: invalid port mapping %d:%d", hostPort, containerPort)
+	}
+
+	allDetails, err := c.GetAppDetailsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var app *AppDefinition
+	for i, v := range allDetails.Data.AppDefinitions {
+		for _, p := range v.Ports {
+			if p.HostPort == hostPort {
+				return fmt.Errorf("%w: host port %d is published by %s", ErrPortInUse, hostPort, v.AppName)
+			}
+		}
+		if v.AppName == appName {
+			app = &allDetails.Data.AppDefinitions[i]
+		}
+	}
+
+	if app == nil {
+		return fmt.Errorf("%w: %s", ErrAppNotFound, appName)
+	}
+
+	currentConfig := newUpdateAppRequest(*app)
+	currentConfig.Ports = append(currentConfig.Ports, PortInformation{
+		HostPort:      hostPort,
+		ContainerPort: containerPort,
+	})
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// RemovePortMapping (appName string, hostPort int) error: This method stops
+// publishing the given host port for an application.
+func (c *Caprover) RemovePortMapping(appName string, hostPort int) error {
+	return c.RemovePortMappingContext(context.Background(), appName, hostPort)
+}
+
+// RemovePortMappingContext is like RemovePortMapping but binds the request to
+// the provided context.
+func (c *Caprover) RemovePortMappingContext(ctx context.Context, appName string, hostPort int) error {
+	currentConfig, err := c.GetDefaultUpdateRequestContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	ports := make([]PortInformation, 0, len(currentConfig.Ports))
+	for _, p := range currentConfig.Ports {
+		if p.HostPort != hostPort {
+			ports = append(ports, p)
+		}
+	}
+
+	if len(ports) == len(currentConfig.Ports) {
+		return fmt.Errorf("crapi: host port %d is not published by %s", hostPort, appName)
+	}
+
+	currentConfig.Ports = ports
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+func isValidPort(port int) bool {
+	return port > 0 && port <= 65535
+}
@@ -0,0 +1,66 @@
+package crapi_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+func TestPortMappings(t *testing.T) {
+	_, caprover := newClient(t)
+
+	for _, name := range []string{"mqtt", "other"} {
+		if err := caprover.CreateApp(name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := caprover.AddPortMapping("mqtt", 1883, 1883); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.AddPortMapping("mqtt", 8883, 8883); err != nil {
+		t.Fatal(err)
+	}
+
+	// A host port can only be published once across all apps.
+	if err := caprover.AddPortMapping("other", 1883, 80); !errors.Is(err, crapi.ErrPortInUse) {
+		t.Errorf("got %v, want ErrPortInUse", err)
+	}
+
+	ports, err := caprover.ListPortMappings("mqtt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 2 || ports[0] != (crapi.PortInformation{HostPort: 1883, ContainerPort: 1883}) {
+		t.Errorf("got %+v", ports)
+	}
+
+	if err := caprover.RemovePortMapping("mqtt", 1883); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.RemovePortMapping("mqtt", 1883); err == nil {
+		t.Error("removed a port that isn't published")
+	}
+	if err := caprover.AddPortMapping("other", 1883, 80); err != nil {
+		t.Errorf("port still in use after removal: %v", err)
+	}
+}
+
+func TestPortMappingsInvalid(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ports := range [][2]int{{0, 80}, {80, 0}, {70000, 80}, {-1, 80}} {
+		if err := caprover.AddPortMapping("app", ports[0], ports[1]); err == nil {
+			t.Errorf("AddPortMapping(%d, %d) accepted", ports[0], ports[1])
+		}
+	}
+
+	if err := caprover.AddPortMapping("missing", 8080, 80); !errors.Is(err, crapi.ErrAppNotFound) {
+		t.Errorf("got %v, want ErrAppNotFound", err)
+	}
+}
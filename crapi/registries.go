@@ -0,0 +1,109 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+)
+
+// Registry types known to Caprover.
+const (
+	RegistryTypeRemote = "REMOTE_REG"
+	RegistryTypeLocal  = "LOCAL_REG"
+)
+
+// ListRegistries () (RegistryList, error): This method retrieves the Docker
+// registries configured on the Caprover instance, along with the id of the
+// default push registry, to which built images are pushed.
+func (c *Caprover) ListRegistries() (RegistryList, error) {
+	return c.ListRegistriesContext(context.Background())
+}
+
+// ListRegistriesContext is like ListRegistries but binds the request to the
+// provided context.
+func (c *Caprover) ListRegistriesContext(ctx context.Context) (RegistryList, error) {
+	c.log().Info("listing registries")
+
+	var rsp RegistriesResponse
+	if err := c.doRequest(ctx, "GET", URLRegistriesPath, nil, &rsp); err != nil {
+		return RegistryList{}, err
+	}
+
+	return rsp.Data, nil
+}
+
+// AddRegistry (registry Registry) error: This method adds a Docker registry to
+// the Caprover instance, used to pull private images and, once made the
+// default push registry, to push built ones. The ID is assigned by Caprover
+// and a zero RegistryType defaults to RegistryTypeRemote.
+func (c *Caprover) AddRegistry(registry Registry) error {
+	return c.AddRegistryContext(context.Background(), registry)
+}
+
+// AddRegistryContext is like AddRegistry but binds the request to the provided
+// context.
+func (c *Caprover) AddRegistryContext(ctx context.Context, registry Registry) error {
+	if registry.RegistryDomain == "" {
+		return errors.New("crapi: registry needs a domain")
+	}
+	if registry.RegistryType == "" {
+		registry.RegistryType = RegistryTypeRemote
+	}
+	registry.ID = ""
+
+	c.log().Info("adding registry", "domain", registry.RegistryDomain)
+
+	return c.doRequest(ctx, "POST", URLRegistriesInsertPath, registry, nil)
+}
+
+// UpdateRegistry (registry Registry) error: This method replaces the
+// credentials and settings of the registry with the given ID.
+func (c *Caprover) UpdateRegistry(registry Registry) error {
+	return c.UpdateRegistryContext(context.Background(), registry)
+}
+
+// UpdateRegistryContext is like UpdateRegistry but binds the request to the
+// provided context.
+func (c *Caprover) UpdateRegistryContext(ctx context.Context, registry Registry) error {
+	if registry.ID == "" {
+		return errors.New("crapi: registry needs an id to be updated")
+	}
+
+	c.log().Info("updating registry", "registry", registry.ID, "domain", registry.RegistryDomain)
+
+	return c.doRequest(ContextWithRetry(ctx), "POST", URLRegistriesUpdatePath, registry, nil)
+}
+
+// DeleteRegistry (registryID string) error: This method removes the registry
+// with the given ID. Caprover refuses to remove the default push registry.
+func (c *Caprover) DeleteRegistry(registryID string) error {
+	return c.DeleteRegistryContext(context.Background(), registryID)
+}
+
+// DeleteRegistryContext is like DeleteRegistry but binds the request to the
+// provided context.
+func (c *Caprover) DeleteRegistryContext(ctx context.Context, registryID string) error {
+	c.log().Info("deleting registry", "registry", registryID)
+
+	data := make(map[string]string)
+	data["registryId"] = registryID
+
+	return c.doRequest(ctx, "POST", URLRegistriesDeletePath, data, nil)
+}
+
+// SetDefaultPushRegistry (registryID string) error: This method makes the
+// registry with the given ID the one built images are pushed to. An empty ID
+// disables pushing built images.
+func (c *Caprover) SetDefaultPushRegistry(registryID string) error {
+	return c.SetDefaultPushRegistryContext(context.Background(), registryID)
+}
+
+// SetDefaultPushRegistryContext is like SetDefaultPushRegistry but binds the
+// request to the provided context.
+func (c *Caprover) SetDefaultPushRegistryContext(ctx context.Context, registryID string) error {
+	c.log().Info("setting default push registry", "registry", registryID)
+
+	data := make(map[string]string)
+	data["registryId"] = registryID
+
+	return c.doRequest(ContextWithRetry(ctx), "POST", URLRegistriesSetPushPath, data, nil)
+}
@@ -0,0 +1,76 @@
+package crapi_test
+
+import (
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+func TestRegistries(t *testing.T) {
+	_, caprover := newClient(t)
+
+	registry := crapi.Registry{
+		RegistryUser:        "ci",
+		RegistryPassword:    "secret",
+		RegistryDomain:      "ghcr.io",
+		RegistryImagePrefix: "example",
+		RegistryType:        crapi.RegistryTypeRemote,
+	}
+	if err := caprover.AddRegistry(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := caprover.ListRegistries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Registries) != 1 || list.Registries[0].ID == "" || list.Registries[0].RegistryDomain != "ghcr.io" {
+		t.Fatalf("got %+v", list)
+	}
+	id := list.Registries[0].ID
+
+	updated := list.Registries[0]
+	updated.RegistryImagePrefix = "other"
+	if err := caprover.UpdateRegistry(updated); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := caprover.SetDefaultPushRegistry(id); err != nil {
+		t.Fatal(err)
+	}
+	list, err = caprover.ListRegistries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.DefaultPushRegistryID != id || list.Registries[0].RegistryImagePrefix != "other" {
+		t.Errorf("got %+v", list)
+	}
+
+	// The default push registry can't be deleted.
+	if err := caprover.DeleteRegistry(id); err == nil {
+		t.Error("deleted the default push registry")
+	}
+	if err := caprover.SetDefaultPushRegistry(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.DeleteRegistry(id); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := caprover.ListRegistries(); len(list.Registries) != 0 {
+		t.Errorf("got %+v after deletion", list)
+	}
+}
+
+func TestRegistriesInvalid(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.AddRegistry(crapi.Registry{RegistryType: crapi.RegistryTypeRemote}); err == nil {
+		t.Error("registry without a domain accepted")
+	}
+	if err := caprover.UpdateRegistry(crapi.Registry{RegistryDomain: "ghcr.io"}); err == nil {
+		t.Error("registry without an id updated")
+	}
+	if err := caprover.DeleteRegistry("missing"); err == nil {
+		t.Error("deleted a registry that doesn't exist")
+	}
+}
@@ -0,0 +1,164 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Caprover instance retries requests that failed
+// because of a transient problem, such as the nginx front answering 502 while
+// captain restarts or the connection being refused.
+//
+// Only idempotent operations are retried: reads and full app definition
+// updates. Mutating calls can opt in per call with ContextWithRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Defaults to 500ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including delays asked
+	// for with a Retry-After header. Zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the delay after every attempt. Defaults to 2.
+	Multiplier float64
+	// Jitter randomly shortens every delay by up to this fraction, between 0
+	// and 1, so that clients don't retry in lockstep.
+	Jitter float64
+	// RetryableStatuses lists the http status codes worth retrying. Defaults
+	// to 429, 502, 503 and 504.
+	RetryableStatuses []int
+}
+
+// DefaultRetryPolicy returns a policy suited for riding out a captain restart.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type retryContextKey struct{}
+
+// ContextWithRetry marks the calls made with the returned context as safe to
+// retry, so that the retry policy also applies to mutating operations such as
+// CreateAppContext or EnableBaseDomainSSLContext.
+func ContextWithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+// retryAllowed reports whether a request may be retried: GET requests always
+// may, others only when their context was marked with ContextWithRetry.
+func retryAllowed(ctx context.Context, method string) bool {
+	if method == http.MethodGet {
+		return true
+	}
+	allowed, _ := ctx.Value(retryContextKey{}).(bool)
+	return allowed
+}
+
+// shouldRetry reports whether err is a transient failure worth retrying.
+func (p RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// The request didn't get a response at all, which is only worth
+		// retrying for network failures, not e.g. a malformed endpoint.
+		return isNetworkError(err)
+	}
+
+	statuses := p.RetryableStatuses
+	if statuses == nil {
+		statuses = DefaultRetryPolicy().RetryableStatuses
+	}
+	for _, status := range statuses {
+		if apiErr.HTTPStatus == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isNetworkError reports whether err comes from the connection to Caprover,
+// such as a refused or reset connection or a truncated response.
+func isNetworkError(err error) bool {
+	// The http client wraps every error in a *url.Error, which is a net.Error
+	// itself, so look at what it wraps.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// backoff returns the delay before the given retry, counted from 1. A valid
+// Retry-After header takes precedence over the computed delay, but is capped
+// by MaxBackoff as well.
+func (p RetryPolicy) backoff(retry int, header http.Header) time.Duration {
+	if delay, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+		return delay
+	}
+
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an http date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,153 @@
+package crapi_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// fastRetries is a retry policy that doesn't slow tests down.
+func fastRetries() crapi.RetryPolicy {
+	policy := crapi.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	srv, caprover := newClient(t, crapi.WithRetryPolicy(fastRetries()))
+
+	srv.FailNext(http.StatusBadGateway, http.StatusServiceUnavailable)
+
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(srv, "GET "+crapi.URLAppListPath); n != 3 {
+		t.Errorf("got %d attempts, want 3", n)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv, caprover := newClient(t, crapi.WithRetryPolicy(fastRetries()))
+
+	srv.FailNext(502, 502, 502, 502, 502)
+
+	_, err := caprover.GetAppDetails()
+	var apiErr *crapi.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadGateway {
+		t.Fatalf("got %v, want http status 502", err)
+	}
+	if n := countRequests(srv, "GET "+crapi.URLAppListPath); n != 4 {
+		t.Errorf("got %d attempts, want 4", n)
+	}
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	srv.FailNext(http.StatusBadGateway)
+
+	if _, err := caprover.GetAppDetails(); err == nil {
+		t.Fatal("request was retried")
+	}
+	if n := countRequests(srv, "GET "+crapi.URLAppListPath); n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+}
+
+func TestRetryPostOnlyWhenAllowed(t *testing.T) {
+	srv, caprover := newClient(t, crapi.WithRetryPolicy(fastRetries()))
+
+	srv.FailNext(http.StatusBadGateway)
+	if err := caprover.CreateApp("app", false); err == nil {
+		t.Fatal("POST was retried")
+	}
+
+	srv.FailNext(http.StatusBadGateway)
+	if err := caprover.CreateAppContext(crapi.ContextWithRetry(context.Background()), "app", false); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(srv, "POST "+crapi.URLAppRegisterPath); n != 3 {
+		t.Errorf("got %d register requests, want 3", n)
+	}
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	// Grab a free port, then close it so that connections are refused.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + listener.Addr().String()
+	listener.Close()
+
+	transport := &countingTransport{}
+	caprover, err := crapi.NewCaproverInstanceWithToken(endpoint, "token",
+		crapi.WithRetryPolicy(fastRetries()),
+		crapi.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := caprover.GetAppDetails(); err == nil {
+		t.Fatal("request to a closed port succeeded")
+	}
+	if n := transport.requests.Load(); n != 4 {
+		t.Errorf("got %d attempts, want 4", n)
+	}
+}
+
+func TestRetryAfterIsClamped(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		emptyAppList(w)
+	}))
+	defer ts.Close()
+
+	caprover, err := crapi.NewCaproverInstanceWithToken(ts.URL, "token", crapi.WithRetryPolicy(fastRetries()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := caprover.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v despite MaxBackoff", elapsed)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d attempts, want 2", n)
+	}
+}
+
+func TestRetryMalformedEndpoint(t *testing.T) {
+	policy := fastRetries()
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	caprover, err := crapi.NewCaproverInstanceWithToken("http://[::1", "token", crapi.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = caprover.GetAppDetailsContext(ctx)
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want an immediate error", err)
+	}
+}
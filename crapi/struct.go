@@ -12,9 +12,12 @@ type LoginResponse struct {
 }
 
 // VolumeInformation holds a single persistant directory info for a given app.
+// The directory is either backed by a Docker volume, named by VolumeName, or
+// bind mounted from HostPath on the host.
 type VolumeInformation struct {
 	ContainerPath string `json:"containerPath"`
-	VolumeName    string `json:"volumeName"`
+	VolumeName    string `json:"volumeName,omitempty"`
+	HostPath      string `json:"hostPath,omitempty"`
 }
 
 // PortInformation holds a single port mapping info for a given app.
@@ -43,40 +46,61 @@ type EnvVarInformation struct {
 	Value string `json:"value"`
 }
 
+// HTTPAuth holds the HTTP basic auth credentials protecting a given app.
+// Caprover only returns the user and the hash of the password; Password is
+// set to change it and, when left empty in an update, the stored hash is kept.
+type HTTPAuth struct {
+	User           string `json:"user"`
+	Password       string `json:"password,omitempty"`
+	PasswordHashed string `json:"passwordHashed,omitempty"`
+}
+
 type AppDeployTokenConfig struct {
 	Enabled bool `json:"enabled"`
 }
 
+// CustomDomain holds a single custom domain of a given app along with its SSL
+// state.
+type CustomDomain struct {
+	PublicDomain string `json:"publicDomain"`
+	HasSsl       bool   `json:"hasSsl"`
+}
+
+// AppVersion holds a single entry of the deployment history of a given app.
+type AppVersion struct {
+	Version           int       `json:"version"`
+	TimeStamp         time.Time `json:"timeStamp"`
+	DeployedImageName string    `json:"deployedImageName"`
+	GitHash           string    `json:"gitHash"`
+}
+
 // AppDefinition holds all the information stored by the caprover for a given app.
 type AppDefinition struct {
-	HasPersistentData                 bool                `json:"hasPersistentData"`
-	Description                       string              `json:"description"`
-	InstanceCount                     int                 `json:"instanceCount"`
-	CaptainDefinitionRelativeFilePath string              `json:"captainDefinitionRelativeFilePath"`
-	Networks                          []string            `json:"networks"`
-	EnvVars                           []EnvVarInformation `json:"envVars"`
-	Volumes                           []VolumeInformation `json:"volumes"`
-	Ports                             []PortInformation   `json:"ports"`
-	Versions                          []struct {
-		Version           int       `json:"version"`
-		TimeStamp         time.Time `json:"timeStamp"`
-		DeployedImageName string    `json:"deployedImageName"`
-		GitHash           string    `json:"gitHash"`
-	} `json:"versions"`
-	DeployedVersion        int                  `json:"deployedVersion"`
-	NotExposeAsWebApp      bool                 `json:"notExposeAsWebApp"`
-	CustomDomain           []any                `json:"customDomain"`
-	HasDefaultSubDomainSsl bool                 `json:"hasDefaultSubDomainSsl"`
-	ForceSsl               bool                 `json:"forceSsl"`
-	WebsocketSupport       bool                 `json:"websocketSupport"`
-	ContainerHTTPPort      int                  `json:"containerHttpPort"`
-	NodeID                 string               `json:"nodeId,omitempty"`
-	PreDeployFunction      string               `json:"preDeployFunction"`
-	ServiceUpdateOverride  string               `json:"serviceUpdateOverride"`
-	AppDeployTokenConfig   AppDeployTokenConfig `json:"appDeployTokenConfig"`
-	AppName                string               `json:"appName"`
-	IsAppBuilding          bool                 `json:"isAppBuilding"`
-	AppPushWebhook         struct {
+	HasPersistentData                 bool                 `json:"hasPersistentData"`
+	Description                       string               `json:"description"`
+	InstanceCount                     int                  `json:"instanceCount"`
+	CaptainDefinitionRelativeFilePath string               `json:"captainDefinitionRelativeFilePath"`
+	Networks                          []string             `json:"networks"`
+	EnvVars                           []EnvVarInformation  `json:"envVars"`
+	Volumes                           []VolumeInformation  `json:"volumes"`
+	Ports                             []PortInformation    `json:"ports"`
+	Versions                          []AppVersion         `json:"versions"`
+	DeployedVersion                   int                  `json:"deployedVersion"`
+	NotExposeAsWebApp                 bool                 `json:"notExposeAsWebApp"`
+	CustomDomain                      []CustomDomain       `json:"customDomain"`
+	HasDefaultSubDomainSsl            bool                 `json:"hasDefaultSubDomainSsl"`
+	ForceSsl                          bool                 `json:"forceSsl"`
+	WebsocketSupport                  bool                 `json:"websocketSupport"`
+	ContainerHTTPPort                 int                  `json:"containerHttpPort"`
+	NodeID                            string               `json:"nodeId,omitempty"`
+	PreDeployFunction                 string               `json:"preDeployFunction"`
+	ServiceUpdateOverride             string               `json:"serviceUpdateOverride"`
+	AppDeployTokenConfig              AppDeployTokenConfig `json:"appDeployTokenConfig"`
+	HTTPAuth                          *HTTPAuth            `json:"httpAuth,omitempty"`
+	CustomNginxConfig                 string               `json:"customNginxConfig"`
+	AppName                           string               `json:"appName"`
+	IsAppBuilding                     bool                 `json:"isAppBuilding"`
+	AppPushWebhook                    struct {
 		TokenVersion     string      `json:"tokenVersion"`
 		PushWebhookToken string      `json:"pushWebhookToken"`
 		RepoInfo         AppRepoInfo `json:"repoInfo"`
@@ -120,6 +144,8 @@ type UpdateAppRequest struct {
 	Description                       string               `json:"description"`
 	EnvVars                           []EnvVarInformation  `json:"envVars"`
 	AppDeployTokenConfig              AppDeployTokenConfig `json:"appDeployTokenConfig"`
+	HTTPAuth                          *HTTPAuth            `json:"httpAuth,omitempty"`
+	CustomNginxConfig                 string               `json:"customNginxConfig"`
 }
 
 // CustomAppRepositoryConfig holds custom app repository information.
@@ -149,14 +175,138 @@ type ServiceUpdateOverride struct {
 	TaskTemplate SUOTaskTemplate `json:"TaskTemplate"`
 }
 
-// AppBuildLogLogs stores the actual build logs as returned by the api
+// CaptainDefinition describes how Caprover builds an app. Exactly one of
+// ImageName, DockerfileLines or TemplateID should be set.
+type CaptainDefinition struct {
+	SchemaVersion   int      `json:"schemaVersion"`
+	ImageName       string   `json:"imageName,omitempty"`
+	DockerfileLines []string `json:"dockerfileLines,omitempty"`
+	TemplateID      string   `json:"templateId,omitempty"`
+}
+
+// Registry holds a Docker registry configured on the Caprover instance.
+// RegistryType is either RegistryTypeRemote or RegistryTypeLocal.
+type Registry struct {
+	ID                  string `json:"id,omitempty"`
+	RegistryUser        string `json:"registryUser"`
+	RegistryPassword    string `json:"registryPassword"`
+	RegistryDomain      string `json:"registryDomain"`
+	RegistryImagePrefix string `json:"registryImagePrefix"`
+	RegistryType        string `json:"registryType"`
+}
+
+// RegistryList holds the registries of the Caprover instance along with the
+// id of the default push registry, empty when built images aren't pushed.
+type RegistryList struct {
+	Registries            []Registry `json:"registries"`
+	DefaultPushRegistryID string     `json:"defaultPushRegistryId"`
+}
+
+// RegistriesResponse is a response bucket for RegistryList
+type RegistriesResponse struct {
+	Status      int          `json:"status"`
+	Description string       `json:"description"`
+	Data        RegistryList `json:"data"`
+}
+
+// ClusterNode holds a single Docker Swarm node of the Caprover cluster. Type
+// is either NodeTypeManager or NodeTypeWorker.
+type ClusterNode struct {
+	NodeID              string `json:"nodeId"`
+	Type                string `json:"type"`
+	IsLeader            bool   `json:"isLeader"`
+	Hostname            string `json:"hostname"`
+	Architecture        string `json:"architecture"`
+	OperatingSystem     string `json:"operatingSystem"`
+	NanoCPU             int64  `json:"nanoCpu"`
+	MemoryBytes         int64  `json:"memoryBytes"`
+	DockerEngineVersion string `json:"dockerEngineVersion"`
+	IP                  string `json:"ip"`
+	State               string `json:"state"`
+	Status              string `json:"status"`
+}
+
+// NodesResponse holds the response for the list nodes request.
+type NodesResponse struct {
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	Data        struct {
+		Nodes []ClusterNode `json:"nodes"`
+	} `json:"data"`
+}
+
+// AddNodeRequest holds the information Caprover needs to join a server to the
+// cluster over SSH.
+type AddNodeRequest struct {
+	NodeType            string `json:"nodeType"`
+	PrivateKey          string `json:"privateKey"`
+	RemoteNodeIPAddress string `json:"remoteNodeIpAddress"`
+	CaptainIPAddress    string `json:"captainIpAddress"`
+	SSHPort             int    `json:"sshPort"`
+	SSHUser             string `json:"sshUser"`
+}
+
+// SystemInfo holds the root domain of the Caprover instance along with the
+// SSL state of its dashboard.
+type SystemInfo struct {
+	RootDomain       string `json:"rootDomain"`
+	CaptainSubDomain string `json:"captainSubDomain"`
+	HasRootSsl       bool   `json:"hasRootSsl"`
+	ForceSsl         bool   `json:"forceSsl"`
+}
+
+// SystemInfoResponse is a response bucket for SystemInfo
+type SystemInfoResponse struct {
+	Status      int        `json:"status"`
+	Description string     `json:"description"`
+	Data        SystemInfo `json:"data"`
+}
+
+// OneClickApp holds a single entry of the one-click app list. BaseURL is the
+// repository the app template comes from.
+type OneClickApp struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	IsOfficial  bool   `json:"isOfficial"`
+	LogoURL     string `json:"logoUrl"`
+	BaseURL     string `json:"baseUrl"`
+}
+
+// OneClickAppListResponse holds the response for the one-click app list
+// request.
+type OneClickAppListResponse struct {
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	Data        struct {
+		OneClickApps []OneClickApp `json:"oneClickApps"`
+	} `json:"data"`
+}
+
+// OneClickAppTemplateResponse holds the response for the one-click app
+// template request.
+type OneClickAppTemplateResponse struct {
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	Data        struct {
+		AppTemplate OneClickAppTemplate `json:"appTemplate"`
+	} `json:"data"`
+}
+
+// AppBuildLogLogs stores the actual build logs as returned by the api. Only
+// the most recent lines are returned; FirstLineNumber is the number of the
+// first of them.
 type AppBuildLogLogs struct {
-	Lines []string `json:"lines"`
+	Lines           []string `json:"lines"`
+	FirstLineNumber int      `json:"firstLineNumber"`
 }
 
-// AppBuildLogData is a data bucket for AppBuildLogLogs
+// AppBuildLogData is a data bucket for AppBuildLogLogs along with the build
+// status of the app
 type AppBuildLogData struct {
-	Logs AppBuildLogLogs `json:"logs"`
+	IsAppBuilding bool            `json:"isAppBuilding"`
+	IsBuildFailed bool            `json:"isBuildFailed"`
+	Logs          AppBuildLogLogs `json:"logs"`
 }
 
 // AppBuildLogResponse is a response bucket for AppBuildLogData
@@ -0,0 +1,157 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// SystemClient groups the operations on the Caprover instance itself rather
+// than on its apps, such as its root domain and the SSL of its dashboard. Get
+// one with Caprover.System.
+//
+// ChangeRootDomain, EnableRootSSL and ForceSSL can move the dashboard to
+// another address, where the client can't read the new settings back until
+// its endpoint is changed. They return the settings read before the change
+// with the change applied, whose DashboardURL is the new address to pass to
+// Caprover.SetEndpoint.
+type SystemClient struct {
+	c *Caprover
+}
+
+// System returns the client for system-level operations of the Caprover
+// instance.
+func (c *Caprover) System() *SystemClient {
+	return &SystemClient{c: c}
+}
+
+// GetInfo () (SystemInfo, error): This method retrieves the root domain of the
+// Caprover instance and whether root SSL is enabled and forced.
+func (s *SystemClient) GetInfo() (SystemInfo, error) {
+	return s.GetInfoContext(context.Background())
+}
+
+// GetInfoContext is like GetInfo but binds the request to the provided
+// context.
+func (s *SystemClient) GetInfoContext(ctx context.Context) (SystemInfo, error) {
+	s.c.log().Info("getting system info")
+
+	var rsp SystemInfoResponse
+	if err := s.c.doRequest(ctx, "GET", URLSystemInfoPath, nil, &rsp); err != nil {
+		return SystemInfo{}, err
+	}
+
+	return rsp.Data, nil
+}
+
+// ChangeRootDomain (rootDomain string, force bool) (SystemInfo, error): This
+// method changes the root domain of the Caprover instance, under which apps
+// and the dashboard are served, e.g. the dashboard moves to
+// captain.<rootDomain>. The DNS of the new domain must already point to the
+// server. When root SSL is enabled, Caprover refuses unless force is set, which
+// disables it. Use SetEndpoint with the DashboardURL of the returned settings
+// when the client talks to the dashboard through its domain.
+func (s *SystemClient) ChangeRootDomain(rootDomain string, force bool) (SystemInfo, error) {
+	return s.ChangeRootDomainContext(context.Background(), rootDomain, force)
+}
+
+// ChangeRootDomainContext is like ChangeRootDomain but binds the requests to
+// the provided context.
+func (s *SystemClient) ChangeRootDomainContext(ctx context.Context, rootDomain string, force bool) (SystemInfo, error) {
+	if rootDomain == "" {
+		return SystemInfo{}, errors.New("crapi: root domain is required")
+	}
+
+	s.c.log().Info("changing root domain", "root_domain", rootDomain, "force", force)
+
+	data := make(map[string]interface{})
+	data["rootDomain"] = rootDomain
+	data["force"] = force
+
+	return s.change(ctx, URLChangeRootDomainPath, data, func(info *SystemInfo) {
+		info.RootDomain = rootDomain
+		// Caprover drops the certificate of the old root domain.
+		info.HasRootSsl = false
+		info.ForceSsl = false
+	})
+}
+
+// EnableRootSSL (emailAddress string) (SystemInfo, error): This method obtains
+// a Let's Encrypt certificate for the dashboard of the Caprover instance, which
+// enables SSL for the apps of the root domain. The email address is used for
+// the Let's Encrypt account.
+func (s *SystemClient) EnableRootSSL(emailAddress string) (SystemInfo, error) {
+	return s.EnableRootSSLContext(context.Background(), emailAddress)
+}
+
+// EnableRootSSLContext is like EnableRootSSL but binds the requests to the
+// provided context.
+func (s *SystemClient) EnableRootSSLContext(ctx context.Context, emailAddress string) (SystemInfo, error) {
+	if !strings.Contains(emailAddress, "@") {
+		return SystemInfo{}, errors.New("crapi: a valid email address is required to enable root SSL")
+	}
+
+	s.c.log().Info("enabling root ssl")
+
+	data := make(map[string]string)
+	data["emailAddress"] = emailAddress
+
+	return s.change(ctx, URLEnableRootSslPath, data, func(info *SystemInfo) {
+		info.HasRootSsl = true
+	})
+}
+
+// ForceSSL (enabled bool) (SystemInfo, error): This method makes the dashboard
+// of the Caprover instance redirect HTTP to HTTPS, or stop doing so. Root SSL
+// must be enabled first, and an endpoint using http must be switched to the
+// https DashboardURL of the returned settings with SetEndpoint afterwards.
+func (s *SystemClient) ForceSSL(enabled bool) (SystemInfo, error) {
+	return s.ForceSSLContext(context.Background(), enabled)
+}
+
+// ForceSSLContext is like ForceSSL but binds the requests to the provided
+// context.
+func (s *SystemClient) ForceSSLContext(ctx context.Context, enabled bool) (SystemInfo, error) {
+	s.c.log().Info("forcing ssl", "enabled", enabled)
+
+	data := make(map[string]bool)
+	data["isEnabled"] = enabled
+
+	return s.change(ContextWithRetry(ctx), URLForceSslPath, data, func(info *SystemInfo) {
+		info.ForceSsl = enabled
+	})
+}
+
+// change reads the settings of the Caprover instance while the dashboard is
+// still at the current endpoint, sends data to path and returns the settings
+// with the change applied.
+func (s *SystemClient) change(ctx context.Context, path string, data interface{}, apply func(*SystemInfo)) (SystemInfo, error) {
+	info, err := s.GetInfoContext(ctx)
+	if err != nil {
+		return SystemInfo{}, err
+	}
+
+	if err := s.c.doRequest(ctx, "POST", path, data, nil); err != nil {
+		return SystemInfo{}, err
+	}
+
+	apply(&info)
+	return info, nil
+}
+
+// DashboardURL returns the https or http address of the dashboard of the
+// Caprover instance, depending on whether SSL is forced, for use with
+// Caprover.SetEndpoint.
+func (i SystemInfo) DashboardURL() string {
+	scheme := "http"
+	if i.HasRootSsl && i.ForceSsl {
+		scheme = "https"
+	}
+
+	subDomain := i.CaptainSubDomain
+	if subDomain == "" {
+		subDomain = "captain"
+	}
+
+	return scheme + "://" + subDomain + "." + i.RootDomain
+}
@@ -0,0 +1,99 @@
+package crapi_test
+
+import (
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+func TestSystem(t *testing.T) {
+	_, caprover := newClient(t)
+	system := caprover.System()
+
+	info, err := system.GetInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.RootDomain != crapitest.DefaultRootDomain || info.HasRootSsl || info.ForceSsl {
+		t.Fatalf("got %+v", info)
+	}
+
+	if _, err := system.ForceSSL(true); err == nil {
+		t.Error("forced SSL before enabling it")
+	}
+	if _, err := system.EnableRootSSL("not an email"); err == nil {
+		t.Error("invalid email address accepted")
+	}
+
+	// Every change returns the settings Caprover reports afterwards.
+	changes := []struct {
+		name      string
+		change    func() (crapi.SystemInfo, error)
+		dashboard string
+	}{
+		{
+			name:      "enable root ssl",
+			change:    func() (crapi.SystemInfo, error) { return system.EnableRootSSL("admin@example.com") },
+			dashboard: "http://captain." + crapitest.DefaultRootDomain,
+		},
+		{
+			name:      "force ssl",
+			change:    func() (crapi.SystemInfo, error) { return system.ForceSSL(true) },
+			dashboard: "https://captain." + crapitest.DefaultRootDomain,
+		},
+		{
+			// Root SSL is lost when the root domain changes, so it must be forced.
+			name:      "change root domain",
+			change:    func() (crapi.SystemInfo, error) { return system.ChangeRootDomain("example.com", true) },
+			dashboard: "http://captain.example.com",
+		},
+	}
+	for _, tt := range changes {
+		got, err := tt.change()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		want, err := system.GetInfo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, want)
+		}
+		if url := got.DashboardURL(); url != tt.dashboard {
+			t.Errorf("%s: got dashboard %s, want %s", tt.name, url, tt.dashboard)
+		}
+	}
+
+	if _, err := system.ChangeRootDomain("example.org", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := system.EnableRootSSL("admin@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := system.ChangeRootDomain("example.com", false); err == nil {
+		t.Error("changed the root domain with root SSL enabled")
+	}
+	if _, err := system.ChangeRootDomain("", false); err == nil {
+		t.Error("empty root domain accepted")
+	}
+}
+
+func TestDashboardURL(t *testing.T) {
+	tests := []struct {
+		info crapi.SystemInfo
+		want string
+	}{
+		{crapi.SystemInfo{RootDomain: "example.com"}, "http://captain.example.com"},
+		{crapi.SystemInfo{RootDomain: "example.com", HasRootSsl: true}, "http://captain.example.com"},
+		{crapi.SystemInfo{RootDomain: "example.com", HasRootSsl: true, ForceSsl: true}, "https://captain.example.com"},
+		{crapi.SystemInfo{RootDomain: "example.com", CaptainSubDomain: "dash"}, "http://dash.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.info.DashboardURL(); got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.info, got, tt.want)
+		}
+	}
+}
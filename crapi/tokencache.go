@@ -0,0 +1,118 @@
+package crapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TokenCache persists authentication tokens between runs so that short-lived
+// programs can reuse a still valid token instead of logging in every time.
+type TokenCache interface {
+	// Load returns the token stored for the endpoint, or an empty string when
+	// there is none.
+	Load(endpoint string) (string, error)
+	// Store saves the token for the endpoint.
+	Store(endpoint string, token string) error
+}
+
+// FileTokenCache is a TokenCache keeping the tokens of every endpoint in a
+// single JSON file readable only by its owner.
+type FileTokenCache struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenCache returns a FileTokenCache backed by the file at path. The
+// file and its directory are created on the first Store.
+func NewFileTokenCache(path string) *FileTokenCache {
+	return &FileTokenCache{path: path}
+}
+
+// DefaultTokenCachePath returns the path of the token cache file in the user
+// cache directory, e.g. ~/.cache/crapi/tokens.json on Linux.
+func DefaultTokenCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "crapi", "tokens.json"), nil
+}
+
+func (f *FileTokenCache) Load(endpoint string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tokens, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	return tokens[tokenCacheKey(endpoint)], nil
+}
+
+func (f *FileTokenCache) Store(endpoint string, token string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tokens, err := f.read()
+	if err != nil {
+		return err
+	}
+	tokens[tokenCacheKey(endpoint)] = token
+
+	encoded, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a concurrent reader never sees
+	// a partially written cache. CreateTemp creates it with 0600 permissions.
+	tmp, err := os.CreateTemp(dir, ".tokens-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(encoded); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// read returns the tokens stored in the cache file, which may not exist yet.
+func (f *FileTokenCache) read() (map[string]string, error) {
+	tokens := make(map[string]string)
+
+	content, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// tokenCacheKey normalises an endpoint so that trailing slashes don't create
+// separate cache entries.
+func tokenCacheKey(endpoint string) string {
+	return strings.TrimRight(endpoint, "/")
+}
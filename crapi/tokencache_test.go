@@ -0,0 +1,82 @@
+package crapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+func TestFileTokenCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crapi", "tokens.json")
+	cache := crapi.NewFileTokenCache(path)
+
+	if token, err := cache.Load("https://captain.example.com"); err != nil || token != "" {
+		t.Fatalf("empty cache: got %q, %v", token, err)
+	}
+
+	if err := cache.Store("https://captain.example.com/", "token-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store("https://other.example.com", "token-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trailing slashes don't make a different endpoint.
+	if token, err := cache.Load("https://captain.example.com"); err != nil || token != "token-1" {
+		t.Errorf("got %q, %v, want token-1", token, err)
+	}
+	if token, err := cache.Load("https://other.example.com"); err != nil || token != "token-2" {
+		t.Errorf("got %q, %v, want token-2", token, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("cache file mode %o, want 600", mode)
+	}
+
+	info, err = os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o700 {
+		t.Errorf("cache directory mode %o, want 700", mode)
+	}
+}
+
+func TestTokenCacheSkipsLogin(t *testing.T) {
+	cache := crapi.NewFileTokenCache(filepath.Join(t.TempDir(), "tokens.json"))
+	srv, first := newClient(t, crapi.WithTokenCache(cache))
+
+	second, err := srv.Client(crapi.WithTokenCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Token() != first.Token() {
+		t.Errorf("got token %q, want the cached %q", second.Token(), first.Token())
+	}
+	if _, err := second.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(srv, "POST "+crapi.URLLoginPath); n != 1 {
+		t.Errorf("got %d login requests, want 1", n)
+	}
+
+	// An expired cached token is replaced after logging in again.
+	srv.ExpireTokens()
+	if _, err := second.GetAppDetails(); err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := cache.Load(srv.URL); token != second.Token() {
+		t.Errorf("cache holds %q, want the renewed %q", token, second.Token())
+	}
+}
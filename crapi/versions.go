@@ -0,0 +1,101 @@
+package crapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListVersions (appName string) ([]AppVersion, error): This method retrieves
+// the deployment history of an application, oldest first, as reported in its
+// app definition.
+func (c *Caprover) ListVersions(appName string) ([]AppVersion, error) {
+	return c.ListVersionsContext(context.Background(), appName)
+}
+
+// ListVersionsContext is like ListVersions but binds the request to the
+// provided context.
+func (c *Caprover) ListVersionsContext(ctx context.Context, appName string) ([]AppVersion, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.Versions, nil
+}
+
+// RollbackTo (appName string, version int) error: This method redeploys the
+// image built for a previous version of an application. Caprover records the
+// rollback as a new version. It returns an error wrapping ErrVersionNotFound
+// when the version doesn't exist or has no image, e.g. because its build
+// failed.
+func (c *Caprover) RollbackTo(appName string, version int) error {
+	return c.RollbackToContext(context.Background(), appName, version)
+}
+
+// RollbackToContext is like RollbackTo but binds the request to the provided
+// context.
+func (c *Caprover) RollbackToContext(ctx context.Context, appName string, version int) error {
+	versions, err := c.ListVersionsContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		if v.Version == version && v.DeployedImageName != "" {
+			c.log().Info("rolling back app", "app", appName, "version", version, "image", v.DeployedImageName)
+			return c.DeployImageContext(ctx, appName, v.DeployedImageName)
+		}
+	}
+
+	return fmt.Errorf("%w: version %d of %s", ErrVersionNotFound, version, appName)
+}
+
+// RollbackToPrevious (appName string) error: This method rolls an application
+// back to the last version with an image deployed before the current one.
+//
+// As Caprover records a rollback as a new version that redeploys an older
+// image, the current image is looked up to its first deployment and the
+// version is picked before that one, with a different image. Calling it again
+// after a rollback thus goes further back in time instead of returning to the
+// version that was just rolled back from.
+func (c *Caprover) RollbackToPrevious(appName string) error {
+	return c.RollbackToPreviousContext(context.Background(), appName)
+}
+
+// RollbackToPreviousContext is like RollbackToPrevious but binds the request to
+// the provided context.
+func (c *Caprover) RollbackToPreviousContext(ctx context.Context, appName string) error {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	current := ""
+	for _, v := range app.Versions {
+		if v.Version == app.DeployedVersion {
+			current = v.DeployedImageName
+		}
+	}
+
+	// Find where the current image was first deployed, which is earlier than
+	// the deployed version when it was redeployed by a rollback.
+	since := app.DeployedVersion
+	for _, v := range app.Versions {
+		if current != "" && v.DeployedImageName == current && v.Version < since {
+			since = v.Version
+		}
+	}
+
+	previous := -1
+	for _, v := range app.Versions {
+		if v.Version < since && v.Version > previous && v.DeployedImageName != "" && v.DeployedImageName != current {
+			previous = v.Version
+		}
+	}
+
+	if previous < 0 {
+		return fmt.Errorf("%w: no version of %s before %d", ErrVersionNotFound, appName, since)
+	}
+
+	return c.RollbackToContext(ctx, appName, previous)
+}
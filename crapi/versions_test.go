@@ -0,0 +1,96 @@
+package crapi_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// deployedImage returns the image of the deployed version of an app.
+func deployedImage(t *testing.T, caprover *crapi.Caprover, appName string) string {
+	t.Helper()
+
+	app, err := caprover.GetAppDetailFor(appName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range app.Versions {
+		if v.Version == app.DeployedVersion {
+			return v.DeployedImageName
+		}
+	}
+	return ""
+}
+
+func TestRollbackTo(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, image := range []string{"app:1", "app:2"} {
+		if err := caprover.DeployImage("app", image); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := caprover.RollbackTo("app", 0); err != nil {
+		t.Fatal(err)
+	}
+	if image := deployedImage(t, caprover, "app"); image != "app:1" {
+		t.Errorf("deployed %s, want app:1", image)
+	}
+
+	versions, err := caprover.ListVersions("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("got %d versions, want the rollback recorded as a third one", len(versions))
+	}
+
+	if err := caprover.RollbackTo("app", 42); !errors.Is(err, crapi.ErrVersionNotFound) {
+		t.Errorf("got %v, want ErrVersionNotFound", err)
+	}
+}
+
+func TestRollbackToPrevious(t *testing.T) {
+	srv, caprover := newClient(t)
+
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.RollbackToPrevious("app"); !errors.Is(err, crapi.ErrVersionNotFound) {
+		t.Errorf("never deployed: got %v, want ErrVersionNotFound", err)
+	}
+
+	for _, image := range []string{"app:1", "app:2", "app:3"} {
+		if err := caprover.DeployImage("app", image); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A failed build doesn't deploy a version to roll back from.
+	srv.FailNextBuild()
+	if err := caprover.DeployImage("app", "app:4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.DeployImage("app", "app:4"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every call goes further back in time.
+	for _, want := range []string{"app:3", "app:2", "app:1"} {
+		if err := caprover.RollbackToPrevious("app"); err != nil {
+			t.Fatal(err)
+		}
+		if image := deployedImage(t, caprover, "app"); image != want {
+			t.Fatalf("deployed %s, want %s", image, want)
+		}
+	}
+
+	if err := caprover.RollbackToPrevious("app"); !errors.Is(err, crapi.ErrVersionNotFound) {
+		t.Errorf("got %v, want ErrVersionNotFound", err)
+	}
+}
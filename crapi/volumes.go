@@ -0,0 +1,117 @@
+package crapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ListVolumes (appName string) ([]VolumeInformation, error): This method
+// retrieves the persistent directories of an application.
+func (c *Caprover) ListVolumes(appName string) ([]VolumeInformation, error) {
+	return c.ListVolumesContext(context.Background(), appName)
+}
+
+// ListVolumesContext is like ListVolumes but binds the request to the provided
+// context.
+func (c *Caprover) ListVolumesContext(ctx context.Context, appName string) ([]VolumeInformation, error) {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.Volumes, nil
+}
+
+// AddVolume (appName string, volume VolumeInformation) error: This method
+// mounts a persistent directory in an application, backed either by a named
+// volume or by a host path. The application must have been created with
+// persistent data, otherwise an error wrapping ErrNoPersistentData is
+// returned. The container path must be absolute and not mounted yet.
+func (c *Caprover) AddVolume(appName string, volume VolumeInformation) error {
+	return c.AddVolumeContext(context.Background(), appName, volume)
+}
+
+// AddVolumeContext is like AddVolume but binds the request to the provided
+// context.
+func (c *Caprover) AddVolumeContext(ctx context.Context, appName string, volume VolumeInformation) error {
+	if err := validateVolume(volume); err != nil {
+		return err
+	}
+
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+	if !app.HasPersistentData {
+		return fmt.Errorf("%w: %s", ErrNoPersistentData, appName)
+	}
+
+	currentConfig := newUpdateAppRequest(app)
+
+	for _, v := range currentConfig.Volumes {
+		if path.Clean(v.ContainerPath) == path.Clean(volume.ContainerPath) {
+			return fmt.Errorf("crapi: %s is already mounted in %s", volume.ContainerPath, appName)
+		}
+	}
+
+	currentConfig.Volumes = append(currentConfig.Volumes, volume)
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// RemoveVolume (appName string, containerPath string) error: This method
+// unmounts the persistent directory mounted at containerPath from an
+// application. The data of a named volume is kept by Caprover.
+func (c *Caprover) RemoveVolume(appName string, containerPath string) error {
+	return c.RemoveVolumeContext(context.Background(), appName, containerPath)
+}
+
+// RemoveVolumeContext is like RemoveVolume but binds the request to the
+// provided context.
+func (c *Caprover) RemoveVolumeContext(ctx context.Context, appName string, containerPath string) error {
+	app, err := c.GetAppDetailForContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+	if !app.HasPersistentData {
+		return fmt.Errorf("%w: %s", ErrNoPersistentData, appName)
+	}
+
+	currentConfig := newUpdateAppRequest(app)
+
+	volumes := make([]VolumeInformation, 0, len(currentConfig.Volumes))
+	for _, v := range currentConfig.Volumes {
+		if path.Clean(v.ContainerPath) != path.Clean(containerPath) {
+			volumes = append(volumes, v)
+		}
+	}
+
+	if len(volumes) == len(currentConfig.Volumes) {
+		return fmt.Errorf("%w: %s in %s", ErrVolumeNotFound, containerPath, appName)
+	}
+
+	currentConfig.Volumes = volumes
+
+	return c.updateAppDetails(ctx, currentConfig)
+}
+
+// validateVolume checks that a volume has an absolute container path and
+// exactly one of a volume name or a host path.
+func validateVolume(volume VolumeInformation) error {
+	if !path.IsAbs(volume.ContainerPath) {
+		return fmt.Errorf("crapi: container path %q must be absolute", volume.ContainerPath)
+	}
+
+	switch {
+	case volume.VolumeName == "" && volume.HostPath == "":
+		return errors.New("crapi: volume needs a volume name or a host path")
+	case volume.VolumeName != "" && volume.HostPath != "":
+		return errors.New("crapi: volume can't have both a volume name and a host path")
+	case volume.HostPath != "" && !path.IsAbs(volume.HostPath):
+		return fmt.Errorf("crapi: host path %q must be absolute", volume.HostPath)
+	}
+
+	return nil
+}
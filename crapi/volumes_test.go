@@ -0,0 +1,74 @@
+package crapi_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+func TestVolumes(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("db", true); err != nil {
+		t.Fatal(err)
+	}
+
+	data := crapi.VolumeInformation{ContainerPath: "/var/lib/data", VolumeName: "db-data"}
+	if err := caprover.AddVolume("db", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.AddVolume("db", crapi.VolumeInformation{ContainerPath: "/etc/conf", HostPath: "/srv/conf"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := caprover.AddVolume("db", crapi.VolumeInformation{ContainerPath: "/var/lib/data/", VolumeName: "other"}); err == nil {
+		t.Error("path mounted twice")
+	}
+
+	volumes, err := caprover.ListVolumes("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(volumes) != 2 || volumes[0] != data {
+		t.Errorf("got %+v", volumes)
+	}
+
+	if err := caprover.RemoveVolume("db", "/etc/conf/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.RemoveVolume("db", "/etc/conf"); !errors.Is(err, crapi.ErrVolumeNotFound) {
+		t.Errorf("got %v, want ErrVolumeNotFound", err)
+	}
+	if volumes, _ := caprover.ListVolumes("db"); len(volumes) != 1 {
+		t.Errorf("got %+v after removal", volumes)
+	}
+}
+
+func TestVolumesInvalid(t *testing.T) {
+	_, caprover := newClient(t)
+
+	if err := caprover.CreateApp("web", false); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := crapi.VolumeInformation{ContainerPath: "/data", VolumeName: "data"}
+	if err := caprover.AddVolume("web", valid); !errors.Is(err, crapi.ErrNoPersistentData) {
+		t.Errorf("got %v, want ErrNoPersistentData", err)
+	}
+	if err := caprover.RemoveVolume("web", "/data"); !errors.Is(err, crapi.ErrNoPersistentData) {
+		t.Errorf("got %v, want ErrNoPersistentData", err)
+	}
+
+	invalid := []crapi.VolumeInformation{
+		{ContainerPath: "data", VolumeName: "data"},
+		{ContainerPath: "/data"},
+		{ContainerPath: "/data", VolumeName: "data", HostPath: "/srv/data"},
+		{ContainerPath: "/data", HostPath: "srv/data"},
+	}
+	for _, volume := range invalid {
+		if err := caprover.AddVolume("web", volume); err == nil || errors.Is(err, crapi.ErrNoPersistentData) {
+			t.Errorf("AddVolume(%+v) = %v, want a validation error", volume, err)
+		}
+	}
+}
@@ -0,0 +1,1081 @@
+// Package crapitest provides an in-memory fake Caprover server for testing
+// code built on the crapi package without a real Caprover instance.
+//
+//	srv := crapitest.NewServer()
+//	defer srv.Close()
+//
+//	caprover, err := srv.Client()
+//
+// The fake keeps apps, environment variables, domains, builds, logs,
+// registries, cluster nodes and authentication tokens in memory and answers
+// with the same status codes as Caprover.
+package crapitest
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+)
+
+// DefaultPassword is the dashboard password of a server created by NewServer.
+const DefaultPassword = "captain42"
+
+// DefaultRootDomain is the root domain reported by a server created by
+// NewServer.
+const DefaultRootDomain = "captain.localhost"
+
+// DefaultNginxConfig is the nginx configuration template returned as the
+// default of every Server, a shortened version of the one of Caprover.
+const DefaultNginxConfig = `<% if (s.forceSsl) { %>
+server {
+    listen 80;
+    server_name <%-s.publicDomain%>;
+    return 302 https://$http_host$request_uri;
+}
+<% } else { %>
+server {
+    listen 80;
+    server_name <%-s.publicDomain%>;
+    client_max_body_size 500m;
+
+    location / {
+        proxy_pass http://<%-s.localDomain%>:<%-s.containerHttpPort%>;
+<% if (s.websocketSupport) { %>
+        proxy_http_version 1.1;
+        proxy_set_header Upgrade $http_upgrade;
+        proxy_set_header Connection "upgrade";
+<% } %>
+    }
+}
+<% } %>
+`
+
+// Server is a fake Caprover instance listening on a local address. It is safe
+// for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	password   string
+	otp        string
+	rootDomain string
+	hasRootSsl bool
+	forceSsl   bool
+	tokens     map[string]bool
+	nextToken  int
+	apps       map[string]*crapi.AppDefinition
+	buildLogs  map[string][]string
+	buildStart map[string]int
+	appLogs    map[string]string
+	failures   []int
+
+	nodes        []crapi.ClusterNode
+	oneClickApps []crapi.OneClickApp
+	templates    map[string]json.RawMessage
+	registries   []crapi.Registry
+	nextRegistry int
+	pushRegistry string
+
+	// Build simulation, see FailNextBuild and SetBuildPolls.
+	failNextBuild bool
+	buildPolls    int
+	building      map[string]int
+	buildFailed   map[string]bool
+	requests      []string
+}
+
+// NewServer starts a fake Caprover server accepting DefaultPassword. The
+// caller must call Close when done with it.
+func NewServer() *Server {
+	s := &Server{
+		password:    DefaultPassword,
+		rootDomain:  DefaultRootDomain,
+		tokens:      make(map[string]bool),
+		apps:        make(map[string]*crapi.AppDefinition),
+		buildLogs:   make(map[string][]string),
+		buildStart:  make(map[string]int),
+		appLogs:     make(map[string]string),
+		building:    make(map[string]int),
+		buildFailed: make(map[string]bool),
+		templates:   make(map[string]json.RawMessage),
+	}
+	s.nodes = []crapi.ClusterNode{newNode(1, crapi.NodeTypeManager, "127.0.0.1")}
+	s.nodes[0].IsLeader = true
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a crapi client logged in to the server.
+func (s *Server) Client(opts ...crapi.Option) (*crapi.Caprover, error) {
+	return crapi.NewCaproverInstance(s.URL, s.Password(), opts...)
+}
+
+// Password returns the dashboard password accepted by the server.
+func (s *Server) Password() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.password
+}
+
+// SetPassword changes the dashboard password accepted by the server.
+func (s *Server) SetPassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password = password
+}
+
+// RequireOTP enables two-factor authentication: logins must send the given
+// one-time password. An empty code disables it again.
+func (s *Server) RequireOTP(code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.otp = code
+}
+
+// ExpireTokens invalidates every token issued so far, as happens when a
+// Caprover token expires.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]bool)
+}
+
+// IssueToken returns a new valid token, as if issued to a CI pipeline.
+func (s *Server) IssueToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken()
+}
+
+// FailNext makes the server answer the next requests with the given http
+// statuses, one per request and in order, before handling requests normally
+// again. Use it to exercise retries.
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// Requests returns the method and path, e.g. "POST /api/v2/login", of every
+// request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// App returns a copy of the definition of the named app.
+func (s *Server) App(appName string) (crapi.AppDefinition, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app, ok := s.apps[appName]
+	if !ok {
+		return crapi.AppDefinition{}, false
+	}
+	return *app, true
+}
+
+// AddApp creates or replaces an app, bypassing the API.
+func (s *Server) AddApp(app crapi.AppDefinition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apps[app.AppName] = &app
+}
+
+// AddNode adds a node to the cluster, bypassing the API. The server starts
+// with a single leader manager node.
+func (s *Server) AddNode(node crapi.ClusterNode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes = append(s.nodes, node)
+}
+
+// AddOneClickApp adds a one-click app to the list served by the server, along
+// with its template in JSON, as Caprover serves it once converted from YAML.
+func (s *Server) AddOneClickApp(app crapi.OneClickApp, template string) error {
+	if !json.Valid([]byte(template)) {
+		return fmt.Errorf("crapitest: template of %s is not valid JSON", app.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.oneClickApps = append(s.oneClickApps, app)
+	s.templates[app.BaseURL+"/"+app.Name] = json.RawMessage(template)
+	return nil
+}
+
+// FailNextBuild makes the next build of any app fail without deploying a new
+// version.
+func (s *Server) FailNextBuild() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNextBuild = true
+}
+
+// SetBuildPolls makes every build appear as running for the given number of
+// build status requests before it finishes. Builds finish immediately by
+// default.
+func (s *Server) SetBuildPolls(polls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buildPolls = polls
+}
+
+// SetAppLogs sets the runtime logs returned for the named app.
+func (s *Server) SetAppLogs(appName string, logs string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appLogs[appName] = logs
+}
+
+// BuildLogs returns the build log lines recorded for the named app, across all
+// its builds.
+func (s *Server) BuildLogs(appName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.buildLogs[appName]...)
+}
+
+// response is the envelope of every Caprover API response.
+type response struct {
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+	Data        any    `json:"data,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, description string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response{
+		Status:      status,
+		Description: description,
+		Data:        data,
+	})
+}
+
+func writeOK(w http.ResponseWriter, description string, data any) {
+	writeJSON(w, crapi.StatusOK, description, data)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<html><body><h1>%d %s</h1></body></html>", status, http.StatusText(status))
+		return
+	}
+
+	if r.URL.Path == crapi.URLLoginPath {
+		s.handleLogin(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/api/v2/user/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Webhook builds are authenticated by their own token.
+	if r.URL.Path == crapi.URLAppTriggerBuild {
+		s.handleTriggerBuild(w, r)
+		return
+	}
+
+	if !s.tokens[r.Header.Get("x-captain-auth")] {
+		writeJSON(w, crapi.StatusAuthTokenInvalid, "Auth token corrupted", nil)
+		return
+	}
+
+	switch {
+	case r.URL.Path == crapi.URLAppListPath:
+		s.handleListApps(w)
+	case r.URL.Path == crapi.URLAppRegisterPath:
+		s.handleRegister(w, r)
+	case r.URL.Path == crapi.URLUpdateAppPath:
+		s.handleUpdate(w, r)
+	case r.URL.Path == crapi.URLAppDeletePath:
+		s.handleDelete(w, r)
+	case r.URL.Path == crapi.URLAppRenamePath:
+		s.handleRename(w, r)
+	case r.URL.Path == crapi.URLEnableBaseDomainSslPath:
+		s.handleEnableBaseDomainSSL(w, r)
+	case r.URL.Path == crapi.URLAddCustomDomainPath:
+		s.handleAddCustomDomain(w, r)
+	case r.URL.Path == crapi.URLEnableCustomDomainSslPath:
+		s.handleEnableCustomDomainSSL(w, r)
+	case r.URL.Path == crapi.URLRemoveCustomDomainPath:
+		s.handleRemoveCustomDomain(w, r)
+	case strings.HasPrefix(r.URL.Path, crapi.URLAppBuildLog+"/"):
+		s.handleAppData(w, r)
+	case r.URL.Path == crapi.URLSystemNodesPath:
+		s.handleNodes(w, r)
+	case r.URL.Path == crapi.URLSystemInfoPath:
+		writeOK(w, "Captain info retrieved", crapi.SystemInfo{
+			RootDomain:       s.rootDomain,
+			CaptainSubDomain: "captain",
+			HasRootSsl:       s.hasRootSsl,
+			ForceSsl:         s.forceSsl,
+		})
+	case r.URL.Path == crapi.URLChangeRootDomainPath:
+		s.handleChangeRootDomain(w, r)
+	case r.URL.Path == crapi.URLEnableRootSslPath:
+		s.handleEnableRootSSL(w, r)
+	case r.URL.Path == crapi.URLForceSslPath:
+		s.handleForceSSL(w, r)
+	case r.URL.Path == crapi.URLOneClickAppListPath:
+		writeOK(w, "All one click apps are retrieved", map[string]any{
+			"oneClickApps": append([]crapi.OneClickApp{}, s.oneClickApps...),
+		})
+	case r.URL.Path == crapi.URLOneClickAppTemplatePath:
+		s.handleOneClickAppTemplate(w, r)
+	case r.URL.Path == crapi.URLRegistriesPath:
+		s.handleListRegistries(w)
+	case r.URL.Path == crapi.URLRegistriesInsertPath:
+		s.handleInsertRegistry(w, r)
+	case r.URL.Path == crapi.URLRegistriesUpdatePath:
+		s.handleUpdateRegistry(w, r)
+	case r.URL.Path == crapi.URLRegistriesDeletePath:
+		s.handleDeleteRegistry(w, r)
+	case r.URL.Path == crapi.URLRegistriesSetPushPath:
+		s.handleSetPushRegistry(w, r)
+	default:
+		writeJSON(w, crapi.StatusErrorGeneric, "Unknown endpoint "+r.URL.Path, nil)
+	}
+}
+
+// decode unmarshals the request body into v, answering with an error when it
+// is not valid JSON.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeJSON(w, crapi.StatusIllegalParameter, "Invalid request body: "+err.Error(), nil)
+		return false
+	}
+	return true
+}
+
+// findApp returns the named app, answering with Caprover's error when it
+// doesn't exist.
+func (s *Server) findApp(w http.ResponseWriter, appName string) (*crapi.AppDefinition, bool) {
+	app, ok := s.apps[appName]
+	if !ok {
+		writeJSON(w, crapi.StatusErrorGeneric, "App could not be found "+appName, nil)
+		return nil, false
+	}
+	return app, true
+}
+
+// findRegistry returns the index of the registry with the given id, answering
+// with an error when there is none.
+func (s *Server) findRegistry(w http.ResponseWriter, id string) (int, bool) {
+	for i, v := range s.registries {
+		if v.ID == id {
+			return i, true
+		}
+	}
+
+	writeJSON(w, crapi.StatusIllegalParameter, "Registry not found: "+id, nil)
+	return 0, false
+}
+
+func (s *Server) issueToken() string {
+	s.nextToken++
+	token := fmt.Sprintf("crapitest-token-%d", s.nextToken)
+	s.tokens[token] = true
+	return token
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Password string `json:"password"`
+		OTPToken string `json:"otpToken"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.Password != s.password {
+		writeJSON(w, crapi.StatusWrongPassword, "Password is incorrect.", nil)
+		return
+	}
+
+	if s.otp != "" {
+		if req.OTPToken == "" {
+			writeJSON(w, crapi.StatusErrorOtpRequired, "Enter OTP token as well", nil)
+			return
+		}
+		if req.OTPToken != s.otp {
+			writeJSON(w, crapi.StatusWrongPassword, "Invalid OTP token", nil)
+			return
+		}
+	}
+
+	writeOK(w, "Login succeeded", map[string]string{"token": s.issueToken()})
+}
+
+func (s *Server) handleListApps(w http.ResponseWriter) {
+	names := make([]string, 0, len(s.apps))
+	for name := range s.apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	apps := make([]crapi.AppDefinition, 0, len(names))
+	for _, name := range names {
+		apps = append(apps, *s.apps[name])
+	}
+
+	writeOK(w, "App definitions are retrieved.", map[string]any{
+		"appDefinitions":     apps,
+		"rootDomain":         s.rootDomain,
+		"defaultNginxConfig": DefaultNginxConfig,
+	})
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppName           string `json:"appName"`
+		HasPersistentData bool   `json:"hasPersistentData"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if crapi.ValidateAppName(req.AppName) != nil {
+		writeJSON(w, crapi.StatusErrorBadName, "App Name is not allowed. Only lowercase letters, numbers and single hyphens are allowed", nil)
+		return
+	}
+
+	if _, ok := s.apps[req.AppName]; ok {
+		writeJSON(w, crapi.StatusErrorAlreadyExist, "App Name already exists. Please use a different name", nil)
+		return
+	}
+
+	app := &crapi.AppDefinition{
+		AppName:           req.AppName,
+		HasPersistentData: req.HasPersistentData,
+		InstanceCount:     1,
+		Networks:          []string{"captain-overlay-network"},
+		EnvVars:           []crapi.EnvVarInformation{},
+		Volumes:           []crapi.VolumeInformation{},
+		Ports:             []crapi.PortInformation{},
+		CustomDomain:      []crapi.CustomDomain{},
+		ContainerHTTPPort: 80,
+	}
+	app.AppPushWebhook.PushWebhookToken = "webhook-" + req.AppName
+	s.apps[req.AppName] = app
+
+	writeOK(w, "App Definition Saved", nil)
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req crapi.UpdateAppRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	app, ok := s.findApp(w, req.AppName)
+	if !ok {
+		return
+	}
+
+	if len(req.Volumes) > 0 && !app.HasPersistentData {
+		writeJSON(w, crapi.StatusIllegalOperation, "Cannot set volumes for apps without persistent data", nil)
+		return
+	}
+
+	// Like Caprover, keep only a hash of the password and keep the stored hash
+	// when no new password is given.
+	var httpAuth *crapi.HTTPAuth
+	switch {
+	case req.HTTPAuth == nil || req.HTTPAuth.User == "":
+	case req.HTTPAuth.Password != "":
+		sum := sha256.Sum256([]byte(req.HTTPAuth.Password))
+		httpAuth = &crapi.HTTPAuth{User: req.HTTPAuth.User, PasswordHashed: hex.EncodeToString(sum[:])}
+	case app.HTTPAuth != nil:
+		httpAuth = &crapi.HTTPAuth{User: req.HTTPAuth.User, PasswordHashed: app.HTTPAuth.PasswordHashed}
+	default:
+		writeJSON(w, crapi.StatusIllegalParameter, "Password is required for HTTP auth", nil)
+		return
+	}
+
+	app.InstanceCount = req.InstanceCount
+	app.CaptainDefinitionRelativeFilePath = req.CaptainDefinitionRelativeFilePath
+	app.NotExposeAsWebApp = req.NotExposeAsWebApp
+	app.ForceSsl = req.ForceSsl
+	app.WebsocketSupport = req.WebsocketSupport
+	app.Volumes = req.Volumes
+	app.Ports = req.Ports
+	app.AppPushWebhook.RepoInfo = req.AppPushWebhook.RepoInfo
+	app.NodeID = req.NodeID
+	app.PreDeployFunction = req.PreDeployFunction
+	app.ServiceUpdateOverride = req.ServiceUpdateOverride
+	app.ContainerHTTPPort = req.ContainerHTTPPort
+	app.Description = req.Description
+	app.EnvVars = req.EnvVars
+	app.AppDeployTokenConfig = req.AppDeployTokenConfig
+	app.HTTPAuth = httpAuth
+	app.CustomNginxConfig = req.CustomNginxConfig
+
+	writeOK(w, "Updated App Definition Saved", nil)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppName string `json:"appName"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if _, ok := s.findApp(w, req.AppName); !ok {
+		return
+	}
+
+	delete(s.apps, req.AppName)
+	delete(s.buildLogs, req.AppName)
+	delete(s.buildStart, req.AppName)
+	delete(s.building, req.AppName)
+	delete(s.buildFailed, req.AppName)
+	delete(s.appLogs, req.AppName)
+
+	writeOK(w, "App is deleted", nil)
+}
+
+func (s *Server) handleRename(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OldAppName string `json:"oldAppName"`
+		NewAppName string `json:"newAppName"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	app, ok := s.findApp(w, req.OldAppName)
+	if !ok {
+		return
+	}
+
+	if crapi.ValidateAppName(req.NewAppName) != nil {
+		writeJSON(w, crapi.StatusErrorBadName, "App Name is not allowed. Only lowercase letters, numbers and single hyphens are allowed", nil)
+		return
+	}
+
+	if _, ok := s.apps[req.NewAppName]; ok {
+		writeJSON(w, crapi.StatusErrorAlreadyExist, "App Name already exists. Please use a different name", nil)
+		return
+	}
+
+	app.AppName = req.NewAppName
+	s.apps[req.NewAppName] = app
+	delete(s.apps, req.OldAppName)
+
+	s.buildLogs[req.NewAppName] = s.buildLogs[req.OldAppName]
+	s.buildStart[req.NewAppName] = s.buildStart[req.OldAppName]
+	s.appLogs[req.NewAppName] = s.appLogs[req.OldAppName]
+	s.building[req.NewAppName] = s.building[req.OldAppName]
+	s.buildFailed[req.NewAppName] = s.buildFailed[req.OldAppName]
+	delete(s.buildLogs, req.OldAppName)
+	delete(s.buildStart, req.OldAppName)
+	delete(s.appLogs, req.OldAppName)
+	delete(s.building, req.OldAppName)
+	delete(s.buildFailed, req.OldAppName)
+
+	writeOK(w, "App renamed", nil)
+}
+
+func (s *Server) handleEnableBaseDomainSSL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppName string `json:"appName"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	app, ok := s.findApp(w, req.AppName)
+	if !ok {
+		return
+	}
+
+	app.HasDefaultSubDomainSsl = true
+
+	writeOK(w, "App is now served over HTTPS", nil)
+}
+
+func (s *Server) handleAddCustomDomain(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppName      string `json:"appName"`
+		CustomDomain string `json:"customDomain"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	app, ok := s.findApp(w, req.AppName)
+	if !ok {
+		return
+	}
+
+	for _, other := range s.apps {
+		for _, d := range other.CustomDomain {
+			if d.PublicDomain == req.CustomDomain {
+				writeJSON(w, crapi.StatusErrorAlreadyExist, "This domain is already in use", nil)
+				return
+			}
+		}
+	}
+
+	app.CustomDomain = append(app.CustomDomain, crapi.CustomDomain{PublicDomain: req.CustomDomain})
+
+	writeOK(w, "Domain is added to the app", nil)
+}
+
+func (s *Server) handleEnableCustomDomainSSL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppName      string `json:"appName"`
+		CustomDomain string `json:"customDomain"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	app, ok := s.findApp(w, req.AppName)
+	if !ok {
+		return
+	}
+
+	for i, d := range app.CustomDomain {
+		if d.PublicDomain == req.CustomDomain {
+			app.CustomDomain[i].HasSsl = true
+			writeOK(w, "Custom domain is now enabled with SSL", nil)
+			return
+		}
+	}
+
+	writeJSON(w, crapi.StatusErrorGeneric, "Custom domain is not attached to app", nil)
+}
+
+func (s *Server) handleRemoveCustomDomain(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AppName      string `json:"appName"`
+		CustomDomain string `json:"customDomain"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	app, ok := s.findApp(w, req.AppName)
+	if !ok {
+		return
+	}
+
+	for i, d := range app.CustomDomain {
+		if d.PublicDomain == req.CustomDomain {
+			app.CustomDomain = append(app.CustomDomain[:i:i], app.CustomDomain[i+1:]...)
+			writeOK(w, "Domain is removed from the app", nil)
+			return
+		}
+	}
+
+	writeJSON(w, crapi.StatusErrorGeneric, "Custom domain is not attached to app", nil)
+}
+
+func (s *Server) handleTriggerBuild(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	for _, app := range s.apps {
+		if token != "" && app.AppPushWebhook.PushWebhookToken == token {
+			s.build(app, "", app.AppPushWebhook.RepoInfo.Repo)
+			writeOK(w, "Build webhook has triggered", nil)
+			return
+		}
+	}
+
+	writeJSON(w, crapi.StatusErrorGeneric, "Invalid token", nil)
+}
+
+// build simulates a build of the app, recording a new version unless the
+// build was set to fail with FailNextBuild.
+func (s *Server) build(app *crapi.AppDefinition, imageName string, source string) {
+	s.building[app.AppName] = s.buildPolls
+	// Like Caprover, which clears its build log when a build starts, only the
+	// lines of the last build are served, numbered from zero.
+	s.buildStart[app.AppName] = len(s.buildLogs[app.AppName])
+
+	if s.failNextBuild {
+		s.failNextBuild = false
+		s.buildFailed[app.AppName] = true
+		s.buildLogs[app.AppName] = append(s.buildLogs[app.AppName],
+			fmt.Sprintf("Build started for %s", app.AppName),
+			fmt.Sprintf("Building from %s", source),
+			"Build has failed!",
+		)
+		return
+	}
+	s.buildFailed[app.AppName] = false
+
+	version := len(app.Versions)
+	if imageName == "" {
+		imageName = fmt.Sprintf("img-captain--%s:%d", app.AppName, version)
+	}
+
+	app.Versions = append(app.Versions, crapi.AppVersion{
+		Version:           version,
+		TimeStamp:         time.Now().UTC(),
+		DeployedImageName: imageName,
+	})
+	app.DeployedVersion = version
+
+	s.buildLogs[app.AppName] = append(s.buildLogs[app.AppName],
+		fmt.Sprintf("Build started for %s", app.AppName),
+		fmt.Sprintf("Building from %s", source),
+		fmt.Sprintf("Build has finished successfully! Deployed %s", imageName),
+	)
+}
+
+func (s *Server) handleAppData(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, crapi.URLAppBuildLog+"/")
+	appName, suffix, _ := strings.Cut(rest, "/")
+
+	if _, ok := s.findApp(w, appName); !ok {
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && suffix == "":
+		start := s.buildStart[appName]
+		lines := append([]string{}, s.buildLogs[appName][start:]...)
+		building := s.building[appName] > 0
+		if building {
+			s.building[appName]--
+		}
+		writeOK(w, "App build status retrieved", map[string]any{
+			"isAppBuilding": building,
+			"isBuildFailed": !building && s.buildFailed[appName],
+			"logs": map[string]any{
+				"lines":           lines,
+				"firstLineNumber": 0,
+			},
+		})
+	case r.Method == http.MethodPost && suffix == "":
+		s.handleDeploy(w, r, appName)
+	case r.Method == http.MethodGet && suffix == "logs":
+		writeOK(w, "App runtime logs are retrieved", map[string]string{
+			"logs": s.appLogs[appName],
+		})
+	default:
+		writeJSON(w, crapi.StatusErrorGeneric, "Unknown endpoint "+r.URL.Path, nil)
+	}
+}
+
+// handleDeploy accepts a source tarball upload or an inline
+// captain-definition and builds it right away.
+func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request, appName string) {
+	app := s.apps[appName]
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		s.handleDeployDefinition(w, r, app)
+		return
+	}
+
+	file, _, err := r.FormFile("sourceFile")
+	if err != nil {
+		writeJSON(w, crapi.StatusIllegalParameter, "Source file is missing", nil)
+		return
+	}
+	defer file.Close()
+
+	if err := checkSourceTarball(file); err != nil {
+		writeJSON(w, crapi.StatusBuildError, err.Error(), nil)
+		return
+	}
+
+	s.build(app, "", "uploaded tarball")
+
+	writeJSON(w, crapi.StatusOKDeployStarted, "Deploy is started", nil)
+}
+
+// handleDeployDefinition deploys an inline captain-definition.
+func (s *Server) handleDeployDefinition(w http.ResponseWriter, r *http.Request, app *crapi.AppDefinition) {
+	var req struct {
+		CaptainDefinitionContent string `json:"captainDefinitionContent"`
+		GitHash                  string `json:"gitHash"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	var def crapi.CaptainDefinition
+	if err := json.Unmarshal([]byte(req.CaptainDefinitionContent), &def); err != nil {
+		writeJSON(w, crapi.StatusIllegalParameter, "Captain definition is not valid JSON", nil)
+		return
+	}
+
+	switch {
+	case def.ImageName != "":
+		s.build(app, def.ImageName, "image "+def.ImageName)
+	case len(def.DockerfileLines) > 0:
+		s.build(app, "", "dockerfile lines")
+	case def.TemplateID != "":
+		s.build(app, "", "template "+def.TemplateID)
+	default:
+		writeJSON(w, crapi.StatusBuildError, "Captain definition has nothing to build", nil)
+		return
+	}
+
+	writeJSON(w, crapi.StatusOKDeployStarted, "Deploy is started", nil)
+}
+
+// checkSourceTarball verifies that the uploaded tar, optionally gzipped,
+// contains a captain-definition file at its root.
+func checkSourceTarball(file io.Reader) error {
+	buffered := bufio.NewReader(file)
+
+	var reader io.Reader = buffered
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s file is missing from the source", crapi.CaptainDefinitionFile)
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimPrefix(header.Name, "./") == crapi.CaptainDefinitionFile {
+			return nil
+		}
+	}
+}
+
+func (s *Server) handleNodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		writeOK(w, "Node info retrieved", map[string]any{
+			"nodes": append([]crapi.ClusterNode{}, s.nodes...),
+		})
+		return
+	}
+
+	var req crapi.AddNodeRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.NodeType != crapi.NodeTypeManager && req.NodeType != crapi.NodeTypeWorker {
+		writeJSON(w, crapi.StatusIllegalParameter, "Node type should be either manager or worker", nil)
+		return
+	}
+	if req.PrivateKey == "" || req.RemoteNodeIPAddress == "" || req.CaptainIPAddress == "" {
+		writeJSON(w, crapi.StatusIllegalParameter, "Private key, remote node IP address and captain IP address are required", nil)
+		return
+	}
+	for _, v := range s.nodes {
+		if v.IP == req.RemoteNodeIPAddress {
+			writeJSON(w, crapi.StatusIllegalParameter, "Node is already part of the cluster: "+v.IP, nil)
+			return
+		}
+	}
+
+	s.nodes = append(s.nodes, newNode(len(s.nodes)+1, req.NodeType, req.RemoteNodeIPAddress))
+
+	writeOK(w, "Docker node is successfully joined.", nil)
+}
+
+// newNode returns a ready node of the fake cluster.
+func newNode(n int, nodeType string, ip string) crapi.ClusterNode {
+	return crapi.ClusterNode{
+		NodeID:              fmt.Sprintf("crapitest-node-%d", n),
+		Type:                nodeType,
+		Hostname:            fmt.Sprintf("crapitest-%s-%d", nodeType, n),
+		Architecture:        "x86_64",
+		OperatingSystem:     "linux",
+		NanoCPU:             2 * crapi.ResourceOneCpu,
+		MemoryBytes:         2048 * crapi.ResourceOneMb,
+		DockerEngineVersion: "24.0.7",
+		IP:                  ip,
+		State:               "ready",
+		Status:              "active",
+	}
+}
+
+func (s *Server) handleChangeRootDomain(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RootDomain string `json:"rootDomain"`
+		Force      bool   `json:"force"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.RootDomain == "" || strings.ContainsAny(req.RootDomain, "/: ") {
+		writeJSON(w, crapi.StatusIllegalParameter, "Invalid root domain: "+req.RootDomain, nil)
+		return
+	}
+	if s.hasRootSsl && !req.Force {
+		writeJSON(w, crapi.StatusIllegalOperation, "SSL is enabled for root. Changing the root domain requires force, which disables root SSL.", nil)
+		return
+	}
+
+	s.rootDomain = req.RootDomain
+	s.hasRootSsl = false
+	s.forceSsl = false
+
+	writeOK(w, "Root domain changed.", nil)
+}
+
+func (s *Server) handleEnableRootSSL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		EmailAddress string `json:"emailAddress"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if !strings.Contains(req.EmailAddress, "@") {
+		writeJSON(w, crapi.StatusIllegalParameter, "Invalid email address: "+req.EmailAddress, nil)
+		return
+	}
+
+	s.hasRootSsl = true
+
+	writeOK(w, "Root SSL Enabled.", nil)
+}
+
+func (s *Server) handleForceSSL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IsEnabled bool `json:"isEnabled"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.IsEnabled && !s.hasRootSsl {
+		writeJSON(w, crapi.StatusIllegalOperation, "You first need to enable SSL on the root domain before forcing it.", nil)
+		return
+	}
+
+	s.forceSsl = req.IsEnabled
+
+	writeOK(w, "Non-SSL traffic is now forbidden.", nil)
+}
+
+func (s *Server) handleOneClickAppTemplate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("appName")
+
+	template, ok := s.templates[query.Get("baseDomain")+"/"+name]
+	if !ok {
+		writeJSON(w, crapi.StatusErrorGeneric, "One click app template not found: "+name, nil)
+		return
+	}
+
+	writeOK(w, "App template is retrieved", map[string]any{"appTemplate": template})
+}
+
+func (s *Server) handleListRegistries(w http.ResponseWriter) {
+	writeOK(w, "All registries retrieved", crapi.RegistryList{
+		Registries:            append([]crapi.Registry{}, s.registries...),
+		DefaultPushRegistryID: s.pushRegistry,
+	})
+}
+
+func (s *Server) handleInsertRegistry(w http.ResponseWriter, r *http.Request) {
+	var req crapi.Registry
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.RegistryType != crapi.RegistryTypeRemote && req.RegistryType != crapi.RegistryTypeLocal {
+		writeJSON(w, crapi.StatusIllegalParameter, "Unknown registry type "+req.RegistryType, nil)
+		return
+	}
+
+	s.nextRegistry++
+	req.ID = fmt.Sprintf("crapitest-registry-%d", s.nextRegistry)
+	s.registries = append(s.registries, req)
+
+	writeOK(w, "Registry is added.", nil)
+}
+
+func (s *Server) handleUpdateRegistry(w http.ResponseWriter, r *http.Request) {
+	var req crapi.Registry
+	if !decode(w, r, &req) {
+		return
+	}
+
+	i, ok := s.findRegistry(w, req.ID)
+	if !ok {
+		return
+	}
+
+	s.registries[i] = req
+
+	writeOK(w, "Registry is updated.", nil)
+}
+
+func (s *Server) handleDeleteRegistry(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RegistryID string `json:"registryId"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	i, ok := s.findRegistry(w, req.RegistryID)
+	if !ok {
+		return
+	}
+
+	if req.RegistryID == s.pushRegistry {
+		writeJSON(w, crapi.StatusIllegalOperation, "Cannot remove the default push. First change the default push.", nil)
+		return
+	}
+
+	s.registries = append(s.registries[:i], s.registries[i+1:]...)
+
+	writeOK(w, "Registry deleted.", nil)
+}
+
+func (s *Server) handleSetPushRegistry(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RegistryID string `json:"registryId"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.RegistryID != "" {
+		if _, ok := s.findRegistry(w, req.RegistryID); !ok {
+			return
+		}
+	}
+
+	s.pushRegistry = req.RegistryID
+
+	writeOK(w, "Push Registry changed.", nil)
+}
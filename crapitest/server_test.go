@@ -0,0 +1,266 @@
+package crapitest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapi"
+	"github.com/ErSauravAdhikari/GoCaproverAPI/crapitest"
+)
+
+// envelope is the body of every Caprover API response.
+type envelope struct {
+	Status      int             `json:"status"`
+	Description string          `json:"description"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// call sends a request to the server, with the token when it isn't empty, and
+// returns the http status and the raw body.
+func call(t *testing.T, srv *crapitest.Server, method string, path string, token string, body any) (int, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("x-captain-auth", token)
+	}
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+
+	content, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rsp.StatusCode, content
+}
+
+// callJSON is like call but decodes the response envelope.
+func callJSON(t *testing.T, srv *crapitest.Server, method string, path string, token string, body any) envelope {
+	t.Helper()
+
+	_, content := call(t, srv, method, path, token, body)
+
+	var rsp envelope
+	if err := json.Unmarshal(content, &rsp); err != nil {
+		t.Fatalf("%s %s: %v in %s", method, path, err, content)
+	}
+	return rsp
+}
+
+// login logs in with the given credentials and returns the response.
+func login(t *testing.T, srv *crapitest.Server, password string, otp string) envelope {
+	t.Helper()
+	return callJSON(t, srv, "POST", crapi.URLLoginPath, "", map[string]string{"password": password, "otpToken": otp})
+}
+
+func TestLoginStatuses(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	if rsp := login(t, srv, "wrong", ""); rsp.Status != crapi.StatusWrongPassword {
+		t.Errorf("wrong password: got status %d", rsp.Status)
+	}
+
+	rsp := login(t, srv, crapitest.DefaultPassword, "")
+	if rsp.Status != crapi.StatusOK {
+		t.Fatalf("right password: got status %d", rsp.Status)
+	}
+	var data struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(rsp.Data, &data); err != nil || data.Token == "" {
+		t.Errorf("got data %s", rsp.Data)
+	}
+
+	srv.RequireOTP("123456")
+	if rsp := login(t, srv, crapitest.DefaultPassword, ""); rsp.Status != crapi.StatusErrorOtpRequired {
+		t.Errorf("missing otp: got status %d", rsp.Status)
+	}
+	if rsp := login(t, srv, crapitest.DefaultPassword, "000000"); rsp.Status != crapi.StatusWrongPassword {
+		t.Errorf("wrong otp: got status %d", rsp.Status)
+	}
+	if rsp := login(t, srv, crapitest.DefaultPassword, "123456"); rsp.Status != crapi.StatusOK {
+		t.Errorf("right otp: got status %d", rsp.Status)
+	}
+
+	srv.SetPassword("changed")
+	if srv.Password() != "changed" {
+		t.Errorf("got password %q", srv.Password())
+	}
+	if rsp := login(t, srv, crapitest.DefaultPassword, "123456"); rsp.Status != crapi.StatusWrongPassword {
+		t.Errorf("old password: got status %d", rsp.Status)
+	}
+}
+
+func TestAuthRequired(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	if rsp := callJSON(t, srv, "GET", crapi.URLAppListPath, "", nil); rsp.Status != crapi.StatusAuthTokenInvalid {
+		t.Errorf("no token: got status %d", rsp.Status)
+	}
+	if rsp := callJSON(t, srv, "GET", crapi.URLAppListPath, "forged", nil); rsp.Status != crapi.StatusAuthTokenInvalid {
+		t.Errorf("forged token: got status %d", rsp.Status)
+	}
+
+	token := srv.IssueToken()
+	if rsp := callJSON(t, srv, "GET", crapi.URLAppListPath, token, nil); rsp.Status != crapi.StatusOK {
+		t.Errorf("issued token: got status %d", rsp.Status)
+	}
+
+	srv.ExpireTokens()
+	if rsp := callJSON(t, srv, "GET", crapi.URLAppListPath, token, nil); rsp.Status != crapi.StatusAuthTokenInvalid {
+		t.Errorf("expired token: got status %d", rsp.Status)
+	}
+
+	if status, _ := call(t, srv, "GET", "/not/api", token, nil); status != http.StatusNotFound {
+		t.Errorf("path outside the api: got http status %d", status)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	token := srv.IssueToken()
+	srv.FailNext(http.StatusBadGateway, http.StatusServiceUnavailable)
+
+	for _, want := range []int{http.StatusBadGateway, http.StatusServiceUnavailable} {
+		status, body := call(t, srv, "GET", crapi.URLAppListPath, token, nil)
+		if status != want || !strings.Contains(string(body), "<html>") {
+			t.Errorf("got http status %d and body %s, want %d with an html page", status, body, want)
+		}
+	}
+
+	if rsp := callJSON(t, srv, "GET", crapi.URLAppListPath, token, nil); rsp.Status != crapi.StatusOK {
+		t.Errorf("after failures: got status %d", rsp.Status)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	login(t, srv, crapitest.DefaultPassword, "")
+	call(t, srv, "GET", crapi.URLAppListPath+"?ignored=1", "", nil)
+
+	want := []string{"POST " + crapi.URLLoginPath, "GET " + crapi.URLAppListPath}
+	if got := srv.Requests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestApps(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	srv.AddApp(crapi.AppDefinition{AppName: "app", InstanceCount: 1})
+
+	app, ok := srv.App("app")
+	if !ok || app.InstanceCount != 1 {
+		t.Fatalf("got %+v, %v", app, ok)
+	}
+
+	// App returns a copy.
+	app.InstanceCount = 5
+	if app, _ := srv.App("app"); app.InstanceCount != 1 {
+		t.Errorf("modifying the copy changed the app")
+	}
+
+	if _, ok := srv.App("missing"); ok {
+		t.Error("found a missing app")
+	}
+
+	token := srv.IssueToken()
+	rsp := callJSON(t, srv, "POST", crapi.URLAppRegisterPath, token, map[string]any{"appName": "app"})
+	if rsp.Status != crapi.StatusErrorAlreadyExist {
+		t.Errorf("duplicate app: got status %d", rsp.Status)
+	}
+	rsp = callJSON(t, srv, "POST", crapi.URLAppRegisterPath, token, map[string]any{"appName": "Bad_Name"})
+	if rsp.Status != crapi.StatusErrorBadName {
+		t.Errorf("bad name: got status %d", rsp.Status)
+	}
+	rsp = callJSON(t, srv, "POST", crapi.URLAppDeletePath, token, map[string]any{"appName": "missing"})
+	if rsp.Status != crapi.StatusErrorGeneric || !strings.Contains(rsp.Description, "not be found") {
+		t.Errorf("missing app: got status %d, %q", rsp.Status, rsp.Description)
+	}
+}
+
+func TestBuildStatus(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	caprover, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := caprover.CreateApp("app", false); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.FailNextBuild()
+	if err := caprover.DeployImage("app", "nginx:1.24"); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetBuildPolls(1)
+	if err := caprover.DeployImage("app", "nginx:1.25"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(srv.BuildLogs("app")); n != 6 {
+		t.Errorf("got %d build log lines across builds, want 6", n)
+	}
+
+	// Only the last build is served, numbered from zero.
+	status, err := caprover.GetBuildStatus("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsAppBuilding || status.Logs.FirstLineNumber != 0 || len(status.Logs.Lines) != 3 {
+		t.Errorf("got %+v", status)
+	}
+
+	status, err = caprover.GetBuildStatus("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.IsAppBuilding || status.IsBuildFailed {
+		t.Errorf("got %+v after the build finished", status)
+	}
+
+	app, _ := srv.App("app")
+	if len(app.Versions) != 1 || app.Versions[0].DeployedImageName != "nginx:1.25" {
+		t.Errorf("got versions %+v", app.Versions)
+	}
+}
+
+func TestAddOneClickApp(t *testing.T) {
+	srv := crapitest.NewServer()
+	defer srv.Close()
+
+	if err := srv.AddOneClickApp(crapi.OneClickApp{Name: "broken"}, "{"); err == nil {
+		t.Error("invalid template accepted")
+	}
+}